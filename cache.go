@@ -1,9 +1,9 @@
 package ndn
 
 import (
-	"container/list"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -17,122 +17,255 @@ type Cache interface {
 	Get(*Interest) *Data
 }
 
+// staleTime returns when d that arrives at t becomes stale for MustBeFresh.
+//
+// ok is false if FreshnessPeriod is absent, because such data never becomes stale.
+func staleTime(d *Data, t time.Time) (stale time.Time, ok bool) {
+	if d.MetaInfo.FreshnessPeriod == 0 {
+		return time.Time{}, false
+	}
+	return t.Add(time.Duration(d.MetaInfo.FreshnessPeriod) * time.Millisecond), true
+}
+
+// SizedCache is a Cache that reports its usage.
+type SizedCache interface {
+	Cache
+	// Len returns the number of data packets.
+	Len() int
+	// Bytes returns the total encoded size of data packets.
+	Bytes() int64
+}
+
 // NewCache creates a new thread-safe in-memory LRU content store
 func NewCache(size int) Cache {
+	return NewPolicyCache(size, NewLRU())
+}
+
+// NewPolicyCache creates a new thread-safe in-memory content store
+// that evicts data packets with the given replacement policy.
+func NewPolicyCache(size int, p Policy) Cache {
 	return &cache{
-		List: list.New(),
-		size: size,
+		entries: make(map[string]*cacheEntry),
+		Policy:  p,
+		size:    size,
+	}
+}
+
+// NewByteCache creates a new thread-safe in-memory content store
+// that is bounded by the total encoded size of data packets in bytes.
+//
+// Data packets are evicted with the given replacement policy until the usage
+// is under maxBytes. A data packet larger than maxBytes is never added,
+// so nothing is added if maxBytes is not positive.
+func NewByteCache(maxBytes int64, p Policy) SizedCache {
+	return &cache{
+		entries:  make(map[string]*cacheEntry),
+		Policy:   p,
+		maxBytes: maxBytes,
+		byBytes:  true,
 	}
 }
 
 type cache struct {
 	cacheMatcher
-	*list.List
-	size int
+	entries map[string]*cacheEntry
+	Policy
+	size     int   // max number of entries unless byBytes
+	maxBytes int64 // max total size of entries if byBytes
+	byBytes  bool
+	bytes    int64
 	sync.Mutex
 }
 
 type cacheEntry struct {
 	*Data
 	time.Time
-	remove func()
+	components []lpm.Component // including implicit digest
+	size       int64
+}
+
+// byteCounter counts bytes written.
+type byteCounter int64
+
+func (n *byteCounter) Write(b []byte) (int, error) {
+	*n += byteCounter(len(b))
+	return len(b), nil
 }
 
 func (c *cache) Add(d *Data) {
 	h := sha256.New()
-	err := d.WriteTo(tlv.NewWriter(h))
+	var size byteCounter
+	err := d.WriteTo(tlv.NewWriter(io.MultiWriter(h, &size)))
 	if err != nil {
 		return
 	}
-	digest := lpm.Component(h.Sum(nil))
+	c.add(d, lpm.Component(h.Sum(nil)), int64(size))
+}
 
-	components := append(d.Name.Components, digest)
-	key := fmt.Sprintf("%s/%s", d.Name, digest)
+// cacheKey identifies a data packet by its full name.
+func cacheKey(name Name, digest lpm.Component) string {
+	return fmt.Sprintf("%s/%s", name, digest)
+}
+
+// add inserts d with its implicit digest and encoded size.
+//
+// It returns false if d is not inserted.
+func (c *cache) add(d *Data, digest lpm.Component, size int64) bool {
+	if c.byBytes && size > c.maxBytes {
+		return false
+	}
+	components := append(d.Name.key(), componentKey(ComponentTypeImplicitSHA256Digest, digest))
+	key := cacheKey(d.Name, digest)
 
 	c.Lock()
 	defer c.Unlock()
 	// check for existing element
-	if m, ok := c.cacheMatcher.Get(components); ok {
-		if elem, ok := m[key]; ok {
-			c.MoveToFront(elem)
-			return
-		}
+	if _, ok := c.entries[key]; ok {
+		c.Hit(key)
+		return false
 	}
 
 	// add new element
-	elem := c.PushFront(cacheEntry{
-		Data: d,
-		Time: time.Now(),
-		remove: func() {
-			c.UpdateAll(components, func(_ []lpm.Component, m map[string]*list.Element) (map[string]*list.Element, bool) {
-				delete(m, key)
-				if len(m) == 0 {
-					return nil, true
-				}
-				return m, false
-			})
-		},
-	})
-	c.UpdateAll(components, func(_ []lpm.Component, m map[string]*list.Element) (map[string]*list.Element, bool) {
+	ent := &cacheEntry{
+		Data:       d,
+		Time:       time.Now(),
+		components: components,
+		size:       size,
+	}
+	c.entries[key] = ent
+	c.bytes += ent.size
+	c.UpdateAll(components, func(_ []lpm.Component, m map[string]*cacheEntry) (map[string]*cacheEntry, bool) {
 		if m == nil {
-			m = make(map[string]*list.Element)
+			m = make(map[string]*cacheEntry)
 		}
-		m[key] = elem
+		m[key] = ent
 		return m, false
 	})
+	c.Policy.Add(key, d)
 
-	// evict oldest element
-	if c.Len() <= c.size {
-		return
+	// evict elements chosen by policy
+	for c.full() {
+		key := c.Evict()
+		if key == "" {
+			break
+		}
+		// key might be already removed regardless of policy
+		c.remove(key)
 	}
-	elem = c.Back()
-	if elem == nil {
-		return
+	return true
+}
+
+// contains checks whether the data packet with the full name exists.
+func (c *cache) contains(name Name, digest lpm.Component) bool {
+	c.Lock()
+	defer c.Unlock()
+	_, ok := c.entries[cacheKey(name, digest)]
+	return ok
+}
+
+// removePrefix removes all data packets under prefix regardless of policy,
+// which forgets them too, and returns them.
+func (c *cache) removePrefix(prefix Name) []*Data {
+	components := prefix.key()
+	if len(prefix.ImplicitDigestSHA256) != 0 {
+		components = append(components, componentKey(ComponentTypeImplicitSHA256Digest, prefix.ImplicitDigestSHA256))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	m, ok := c.cacheMatcher.Get(components)
+	if !ok {
+		return nil
+	}
+	var removed []*Data
+	for key, ent := range m {
+		removed = append(removed, ent.Data)
+		c.Policy.Remove(key)
+		c.remove(key)
+	}
+	return removed
+}
+
+func (c *cache) full() bool {
+	if c.byBytes {
+		return c.bytes > c.maxBytes
+	}
+	return len(c.entries) > c.size
+}
+
+func (c *cache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.entries)
+}
+
+func (c *cache) Bytes() int64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.bytes
+}
+
+// remove removes an element that is already forgotten by policy.
+func (c *cache) remove(key string) bool {
+	ent, ok := c.entries[key]
+	if !ok {
+		return false
 	}
-	c.Remove(elem).(cacheEntry).remove()
+	delete(c.entries, key)
+	c.bytes -= ent.size
+	c.UpdateAll(ent.components, func(_ []lpm.Component, m map[string]*cacheEntry) (map[string]*cacheEntry, bool) {
+		delete(m, key)
+		if len(m) == 0 {
+			return nil, true
+		}
+		return m, false
+	})
+	return true
 }
 
 func (c *cache) Get(i *Interest) *Data {
-	components := i.Name.Components
+	components := i.Name.key()
 	if len(i.Name.ImplicitDigestSHA256) != 0 {
-		components = append(components, i.Name.ImplicitDigestSHA256)
+		components = append(components, componentKey(ComponentTypeImplicitSHA256Digest, i.Name.ImplicitDigestSHA256))
 	}
 
 	c.Lock()
 	defer c.Unlock()
-	var match *list.Element
+	var (
+		match    *cacheEntry
+		matchKey string
+	)
 	m, ok := c.cacheMatcher.Get(components)
 	if !ok {
 		return nil
 	}
-	for _, elem := range m {
-		ent := elem.Value.(cacheEntry)
-		if !i.Selectors.Match(ent.Data, i.Name.Len()) {
+	for key, ent := range m {
+		if !i.Match(ent.Data) {
 			continue
 		}
-		if i.Selectors.MustBeFresh && ent.MetaInfo.FreshnessPeriod > 0 &&
-			time.Since(ent.Time) > time.Duration(ent.MetaInfo.FreshnessPeriod)*time.Millisecond {
+		if stale, ok := staleTime(ent.Data, ent.Time); ok && (i.MustBeFresh || i.Selectors.MustBeFresh) &&
+			time.Now().After(stale) {
 			continue
 		}
 		if match == nil {
-			match = elem
+			match, matchKey = ent, key
 		} else {
-			cmp := ent.Name.Compare(match.Value.(cacheEntry).Name)
+			cmp := ent.Name.Compare(match.Name)
 			switch i.Selectors.ChildSelector {
 			case 0:
 				if cmp < 0 {
-					match = elem
+					match, matchKey = ent, key
 				}
 			case 1:
 				if cmp > 0 {
-					match = elem
+					match, matchKey = ent, key
 				}
 			}
 		}
 	}
 	if match != nil {
-		c.MoveToFront(match)
-		return match.Value.(cacheEntry).Data
+		c.Hit(matchKey)
+		return match.Data
 	}
 	return nil
 }
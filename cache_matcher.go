@@ -1,27 +1,26 @@
 package ndn
 
 import (
-	"container/list"
 	"github.com/go-ndn/lpm"
 )
 
 type cacheMatcher struct{ cacheNode }
 type cacheNode struct {
-	val   *map[string]*list.Element
+	val   *map[string]*cacheEntry
 	table map[string]*cacheNode
 }
 
 func (n *cacheNode) Empty() bool {
 	return n.val == nil && len(n.table) == 0
 }
-func cacheDeref(val *map[string]*list.Element) (map[string]*list.Element, bool) {
+func cacheDeref(val *map[string]*cacheEntry) (map[string]*cacheEntry, bool) {
 	if val == nil {
-		var t map[string]*list.Element
+		var t map[string]*cacheEntry
 		return t, false
 	}
 	return *val, true
 }
-func (n *cacheNode) Match(key []lpm.Component) (val map[string]*list.Element, found bool) {
+func (n *cacheNode) Match(key []lpm.Component) (val map[string]*cacheEntry, found bool) {
 	if len(key) == 0 {
 		return cacheDeref(n.val)
 	}
@@ -34,7 +33,7 @@ func (n *cacheNode) Match(key []lpm.Component) (val map[string]*list.Element, fo
 	}
 	return child.Match(key[1:])
 }
-func (n *cacheNode) Get(key []lpm.Component) (val map[string]*list.Element, found bool) {
+func (n *cacheNode) Get(key []lpm.Component) (val map[string]*cacheEntry, found bool) {
 	if len(key) == 0 {
 		return cacheDeref(n.val)
 	}
@@ -47,7 +46,7 @@ func (n *cacheNode) Get(key []lpm.Component) (val map[string]*list.Element, foun
 	}
 	return child.Get(key[1:])
 }
-func (n *cacheNode) Update(key []lpm.Component, val map[string]*list.Element) {
+func (n *cacheNode) Update(key []lpm.Component, val map[string]*cacheEntry) {
 	if len(key) == 0 {
 		n.val = &val
 		return
@@ -78,7 +77,7 @@ func (n *cacheNode) Delete(key []lpm.Component) {
 	}
 }
 
-type cacheUpdateFunc func([]lpm.Component, map[string]*list.Element) (val map[string]*list.Element, del bool)
+type cacheUpdateFunc func([]lpm.Component, map[string]*cacheEntry) (val map[string]*cacheEntry, del bool)
 
 func (n *cacheNode) UpdateAll(key []lpm.Component, f cacheUpdateFunc) {
 	for i := len(key); i > 0; i-- {
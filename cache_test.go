@@ -1,6 +1,10 @@
 package ndn
 
-import "testing"
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
 
 func TestCache(t *testing.T) {
 	c := NewCache(5)
@@ -22,6 +26,7 @@ func TestCache(t *testing.T) {
 		in            string
 		want          string
 		childSelector uint64
+		canBePrefix   bool
 		digestSHA256  []byte
 	}{
 		{
@@ -31,8 +36,12 @@ func TestCache(t *testing.T) {
 			digestSHA256:  []byte{0xb8, 0x58, 0x3b, 0xf2, 0x4f, 0xd0, 0xcd, 0x1a, 0x64, 0xb6, 0x71, 0xc7, 0x67, 0x7f, 0x9, 0x89, 0xf4, 0xef, 0xad, 0x54, 0x9a, 0x93, 0xdc, 0x7e, 0x52, 0x31, 0xaa, 0x18, 0x99, 0x96, 0x50, 0x95},
 		},
 		{
-			in:   "/D",
-			want: "/D/E",
+			in:          "/D",
+			want:        "/D/E",
+			canBePrefix: true,
+		},
+		{
+			in: "/D",
 		},
 		{
 			in:   "/A",
@@ -58,7 +67,8 @@ func TestCache(t *testing.T) {
 		name := NewName(test.in)
 		name.ImplicitDigestSHA256 = test.digestSHA256
 		d := c.Get(&Interest{
-			Name: name,
+			Name:        name,
+			CanBePrefix: test.canBePrefix,
 			Selectors: Selectors{
 				ChildSelector: test.childSelector,
 			},
@@ -72,3 +82,167 @@ func TestCache(t *testing.T) {
 		}
 	}
 }
+
+func TestCachePolicy(t *testing.T) {
+	for _, test := range []struct {
+		policy Policy
+		// A, B, C are added in order, and A is accessed twice before D is added.
+		evicted string
+	}{
+		{NewLRU(), "/B"},
+		{NewFIFO(), "/A"},
+		{NewLFU(), "/B"},
+		{NewFreshness(), "/C"},
+		{NewARC(3), "/B"},
+	} {
+		c := NewPolicyCache(3, test.policy)
+		for i, name := range []string{"/A", "/B", "/C"} {
+			c.Add(&Data{
+				Name: NewName(name),
+				MetaInfo: MetaInfo{
+					FreshnessPeriod: uint64(3-i) * 1000,
+				},
+			})
+		}
+		for i := 0; i < 2; i++ {
+			c.Get(&Interest{Name: NewName("/A")})
+		}
+		c.Add(&Data{
+			Name: NewName("/D"),
+			MetaInfo: MetaInfo{
+				FreshnessPeriod: 4000,
+			},
+		})
+		for _, name := range []string{"/A", "/B", "/C", "/D"} {
+			got := c.Get(&Interest{Name: NewName(name)}) != nil
+			want := name != test.evicted
+			if got != want {
+				t.Fatalf("%T: expect %v to be evicted", test.policy, test.evicted)
+			}
+		}
+	}
+
+	// data without FreshnessPeriod is fresh, so it is evicted last
+	c := NewPolicyCache(2, NewFreshness())
+	for i, name := range []string{"/A", "/B", "/C"} {
+		c.Add(&Data{
+			Name: NewName(name),
+			MetaInfo: MetaInfo{
+				FreshnessPeriod: uint64(i) * 1000,
+			},
+		})
+	}
+	for _, name := range []string{"/A", "/B", "/C"} {
+		got := c.Get(&Interest{Name: NewName(name), MustBeFresh: true}) != nil
+		if want := name != "/B"; got != want {
+			t.Fatalf("expect /B to be evicted, and others to be fresh")
+		}
+	}
+
+	// removed keys are never evicted
+	for _, p := range []Policy{NewLRU(), NewFIFO(), NewLFU(), NewFreshness(), NewARC(3)} {
+		for _, key := range []string{"A", "B"} {
+			p.Add(key, &Data{})
+		}
+		p.Remove("A")
+		for _, want := range []string{"B", ""} {
+			if got := p.Evict(); got != want {
+				t.Fatalf("%T: expect %q, got %q", p, want, got)
+			}
+		}
+	}
+
+	// adding an existing key again does not duplicate it
+	p := NewLRU()
+	for _, key := range []string{"A", "B", "A"} {
+		p.Add(key, nil)
+	}
+	for _, want := range []string{"B", "A", ""} {
+		if got := p.Evict(); got != want {
+			t.Fatalf("expect %q, got %q", want, got)
+		}
+	}
+}
+
+func BenchmarkCachePolicy(b *testing.B) {
+	const (
+		size  = 100
+		names = 1000
+	)
+	var data [names]*Data
+	for i := range data {
+		data[i] = &Data{
+			Name: NewName(fmt.Sprintf("/%d", i)),
+			MetaInfo: MetaInfo{
+				FreshnessPeriod: uint64(i % 10 * 1000),
+			},
+		}
+	}
+	for _, test := range []struct {
+		name   string
+		policy func() Policy
+	}{
+		{"LRU", NewLRU},
+		{"FIFO", NewFIFO},
+		{"LFU", NewLFU},
+		{"Freshness", NewFreshness},
+		{"ARC", func() Policy { return NewARC(size) }},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			c := NewPolicyCache(size, test.policy())
+			zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, names-1)
+			var hit int
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d := data[zipf.Uint64()]
+				if c.Get(&Interest{Name: d.Name}) != nil {
+					hit++
+				} else {
+					c.Add(d)
+				}
+			}
+			b.ReportMetric(float64(hit)/float64(b.N), "hits/op")
+		})
+	}
+}
+
+func TestByteCache(t *testing.T) {
+	c := NewByteCache(1000, NewLRU())
+	for i := 0; i < 10; i++ {
+		c.Add(&Data{
+			Name:    NewName(fmt.Sprintf("/%d", i)),
+			Content: make([]byte, 200),
+		})
+		if c.Bytes() > 1000 {
+			t.Fatalf("expect at most 1000 bytes, got %d", c.Bytes())
+		}
+	}
+	if c.Len() != 4 {
+		t.Fatalf("expect 4 entries, got %d", c.Len())
+	}
+	for i := 0; i < 10; i++ {
+		got := c.Get(&Interest{Name: NewName(fmt.Sprintf("/%d", i))}) != nil
+		want := i >= 6
+		if got != want {
+			t.Fatalf("Get(/%d) == %v, got %v", i, want, got)
+		}
+	}
+
+	c.Add(&Data{
+		Name:    NewName("/large"),
+		Content: make([]byte, 1000),
+	})
+	if c.Len() != 4 || c.Get(&Interest{Name: NewName("/large")}) != nil {
+		t.Fatal("data larger than the budget should not be added")
+	}
+
+	for _, maxBytes := range []int64{0, -1} {
+		c := NewByteCache(maxBytes, NewLRU())
+		c.Add(&Data{
+			Name: NewName("/A"),
+		})
+		if c.Len() != 0 || c.Get(&Interest{Name: NewName("/A")}) != nil {
+			t.Fatalf("expect nothing to be added with maxBytes %d", maxBytes)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package ndn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"github.com/go-ndn/lpm"
+)
+
+// Errors introduced by Certificate.
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate")
+)
+
+// Name components of certificate naming conventions.
+var (
+	keyComponent  = lpm.Component("KEY")
+	selfIssuerID  = lpm.Component("self")
+	defaultIssuer = lpm.Component("NA")
+)
+
+// ContentTypeKey is the ContentType of certificates.
+const ContentTypeKey = 2
+
+// selfSignedValidity is the validity of certificates created by CertificateToData.
+const selfSignedValidity = 20 * 365 * 24 * time.Hour
+
+// NewKeyName creates a key name /<identity>/KEY/<key-id> with a random 8-byte key id.
+func NewKeyName(identity Name) (Name, error) {
+	keyID := make([]byte, 8)
+	_, err := rand.Read(keyID)
+	if err != nil {
+		return Name{}, err
+	}
+	return identity.Append(ComponentTypeGeneric, keyComponent).Append(ComponentTypeGeneric, keyID), nil
+}
+
+// isKeyName checks whether n is named /<identity>/KEY/<key-id>.
+func isKeyName(n Name) bool {
+	l := n.Len()
+	return l >= 2 && bytes.Equal(n.Components[l-2], keyComponent)
+}
+
+// isCertificateName checks whether n is named /<identity>/KEY/<key-id>/<issuer-id>/<version>.
+func isCertificateName(n Name) bool {
+	l := n.Len()
+	return l >= 4 && bytes.Equal(n.Components[l-4], keyComponent)
+}
+
+// Certificate is a data packet that carries a public key,
+// following NDN certificate format v2.
+//
+// It is named /<identity>/KEY/<key-id>/<issuer-id>/<version>,
+// and signed by the issuer with ValidityPeriod.
+//
+// See https://named-data.net/doc/ndn-cxx/current/specs/certificate-format.html.
+type Certificate struct {
+	*Data
+	// Key is the public key in Content.
+	// Its locator is the key name.
+	Key       Key
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// NewCertificate creates a certificate of key, and signs it with issuer.
+//
+// The locator of key must be a key name /<identity>/KEY/<key-id>.
+// If issuerID is nil, it is "self" when key is issuer, or "NA" otherwise.
+func NewCertificate(key, issuer Key, issuerID lpm.Component, notBefore, notAfter time.Time) (*Certificate, error) {
+	keyName := key.Locator()
+	if !isKeyName(keyName) {
+		return nil, ErrInvalidCertificate
+	}
+	if issuerID == nil {
+		if key == issuer {
+			issuerID = selfIssuerID
+		} else {
+			issuerID = defaultIssuer
+		}
+	}
+	pub, err := key.Public()
+	if err != nil {
+		return nil, err
+	}
+	d := &Data{
+		Name: keyName.
+			Append(ComponentTypeGeneric, issuerID).
+			AppendVersion(timeVersion(time.Now())),
+		MetaInfo: MetaInfo{
+			ContentType:     ContentTypeKey,
+			FreshnessPeriod: 3600000, // 1 hour
+		},
+		Content: pub,
+		SignatureInfo: SignatureInfo{
+			ValidityPeriod: ValidityPeriod{
+				NotBefore: notBefore.UTC().Format(ISO8601),
+				NotAfter:  notAfter.UTC().Format(ISO8601),
+			},
+		},
+	}
+	err = SignData(issuer, d)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificate(d)
+}
+
+// ParseCertificate parses a data packet as a certificate.
+//
+// Signature will not be verified.
+func ParseCertificate(d *Data) (*Certificate, error) {
+	if !isCertificateName(d.Name) || d.MetaInfo.ContentType != ContentTypeKey {
+		return nil, ErrInvalidCertificate
+	}
+	notBefore, err := time.Parse(ISO8601, d.SignatureInfo.ValidityPeriod.NotBefore)
+	if err != nil {
+		return nil, ErrInvalidCertificate
+	}
+	notAfter, err := time.Parse(ISO8601, d.SignatureInfo.ValidityPeriod.NotAfter)
+	if err != nil {
+		return nil, ErrInvalidCertificate
+	}
+	key, err := publicKey(d.Name.Prefix(d.Name.Len()-2), d.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{
+		Data:      d,
+		Key:       key,
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+	}, nil
+}
+
+// publicKey decodes a public key in PKIX encoding.
+func publicKey(name Name, b []byte) (key Key, err error) {
+	pub, err := x509.ParsePKIXPublicKey(b)
+	if err != nil {
+		return
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		key = &RSAKey{
+			Name: name,
+			PrivateKey: &rsa.PrivateKey{
+				PublicKey: *pub,
+			},
+		}
+	case *ecdsa.PublicKey:
+		key = &ECDSAKey{
+			Name: name,
+			PrivateKey: &ecdsa.PrivateKey{
+				PublicKey: *pub,
+			},
+		}
+	case ed25519.PublicKey:
+		key = &Ed25519Key{
+			Name:      name,
+			PublicKey: pub,
+		}
+	default:
+		err = ErrNotSupported
+	}
+	return
+}
+
+// KeyName returns /<identity>/KEY/<key-id>.
+func (cert *Certificate) KeyName() Name {
+	return cert.Name.Prefix(cert.Name.Len() - 2)
+}
+
+// Identity returns the identity that owns the key.
+func (cert *Certificate) Identity() Name {
+	return cert.Name.Prefix(cert.Name.Len() - 4)
+}
+
+// KeyID returns the key id component.
+func (cert *Certificate) KeyID() lpm.Component {
+	return cert.Name.Components[cert.Name.Len()-3]
+}
+
+// IssuerID returns the issuer id component.
+func (cert *Certificate) IssuerID() lpm.Component {
+	return cert.Name.Components[cert.Name.Len()-2]
+}
+
+// Issuer returns the name of the key or certificate that signs the certificate.
+func (cert *Certificate) Issuer() Name {
+	return cert.SignatureInfo.KeyLocator.Name
+}
+
+// SelfSigned checks whether the certificate is signed by its own key.
+func (cert *Certificate) SelfSigned() bool {
+	issuer := cert.Issuer()
+	if isCertificateName(issuer) {
+		issuer = issuer.Prefix(issuer.Len() - 2)
+	}
+	return issuer.Compare(cert.KeyName()) == 0
+}
+
+// Valid checks whether t is in ValidityPeriod.
+func (cert *Certificate) Valid(t time.Time) bool {
+	return !t.Before(cert.NotBefore) && !t.After(cert.NotAfter)
+}
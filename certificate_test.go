@@ -0,0 +1,71 @@
+package ndn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertificateIssuer(t *testing.T) {
+	now := time.Now()
+	anchor, err := ParseCertificate(mustCertificateToData(t, rsaKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !anchor.SelfSigned() {
+		t.Fatal("expect self-signed certificate")
+	}
+	if got := string(anchor.IssuerID()); got != "self" {
+		t.Fatalf("expect issuer id self, got %s", got)
+	}
+
+	cert, err := NewCertificate(ecdsaKey, rsaKey, nil, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		got, want string
+	}{
+		{cert.Identity().String(), "/ndn/guest/alice/1434508996774"},
+		{cert.KeyName().String(), ecdsaKey.Locator().String()},
+		{string(cert.KeyID()), "\x00\x00"},
+		{string(cert.IssuerID()), "NA"},
+		{cert.Issuer().String(), rsaKey.Locator().String()},
+	} {
+		if test.got != test.want {
+			t.Fatalf("expect %q, got %q", test.want, test.got)
+		}
+	}
+	if _, ok := cert.Name.Version(); !ok {
+		t.Fatal("expect version")
+	}
+	if cert.SelfSigned() {
+		t.Fatal("expect issuer-signed certificate")
+	}
+	if !cert.Valid(now) || cert.Valid(now.Add(2*time.Hour)) {
+		t.Fatalf("unexpected validity %v-%v", cert.NotBefore, cert.NotAfter)
+	}
+
+	err = VerifyData(anchor.Key, cert.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyData(cert.Key, cert.Data)
+	if err == nil {
+		t.Fatal("expect certificate not signed by its own key")
+	}
+
+	_, err = ParseCertificate(&Data{
+		Name: NewName("/ndn/guest/alice"),
+	})
+	if err != ErrInvalidCertificate {
+		t.Fatalf("expect %v, got %v", ErrInvalidCertificate, err)
+	}
+}
+
+func mustCertificateToData(t *testing.T, key Key) *Data {
+	d, err := CertificateToData(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
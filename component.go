@@ -0,0 +1,224 @@
+package ndn
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/go-ndn/lpm"
+)
+
+// ComponentType specifies TLV-TYPE of name components.
+//
+// See https://named-data.net/doc/NDN-packet-spec/current/name.html#name-component-type
+// and https://named-data.net/publications/techreports/ndn-tr-22-3-ndn-memo-naming-conventions/.
+const (
+	ComponentTypeImplicitSHA256Digest   uint64 = 1
+	ComponentTypeParametersSHA256Digest uint64 = 2
+	ComponentTypeGeneric                uint64 = 8
+	ComponentTypeKeyword                uint64 = 32
+	ComponentTypeSegment                uint64 = 50
+	ComponentTypeByteOffset             uint64 = 52
+	ComponentTypeVersion                uint64 = 54
+	ComponentTypeTimestamp              uint64 = 56
+	ComponentTypeSequenceNumber         uint64 = 58
+)
+
+// componentAlias is the URI representation of numeric typed components.
+var componentAlias = map[uint64]string{
+	ComponentTypeSegment:        "seg",
+	ComponentTypeByteOffset:     "off",
+	ComponentTypeVersion:        "v",
+	ComponentTypeTimestamp:      "t",
+	ComponentTypeSequenceNumber: "seq",
+}
+
+// encodeNumber encodes NonNegativeInteger.
+func encodeNumber(v uint64) lpm.Component {
+	var b []byte
+	switch {
+	case v <= 0xff:
+		b = []byte{byte(v)}
+	case v <= 0xffff:
+		b = make([]byte, 2)
+		binary.BigEndian.PutUint16(b, uint16(v))
+	case v <= 0xffffffff:
+		b = make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(v))
+	default:
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+	}
+	return b
+}
+
+// decodeNumber decodes NonNegativeInteger.
+func decodeNumber(b []byte) (uint64, error) {
+	switch len(b) {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case 8:
+		return binary.BigEndian.Uint64(b), nil
+	default:
+		return 0, ErrNotSupported
+	}
+}
+
+// Append returns a new name with a component of the given TLV-TYPE appended.
+//
+// The original name is not modified.
+func (n Name) Append(t uint64, v lpm.Component) Name {
+	n2 := Name{
+		Components: make([]lpm.Component, len(n.Components), len(n.Components)+1),
+	}
+	copy(n2.Components, n.Components)
+	if len(n.types) != 0 {
+		n2.types = make([]uint64, len(n.types), len(n.Components)+1)
+		copy(n2.types, n.types)
+	}
+	n2.push(t, v)
+	return n2
+}
+
+// Prefix returns the first l components of the name.
+//
+// The original name is not modified. ImplicitDigestSHA256 is not included.
+func (n Name) Prefix(l int) Name {
+	if l > len(n.Components) {
+		l = len(n.Components)
+	}
+	var n2 Name
+	for i := 0; i < l; i++ {
+		n2.push(n.Type(i), n.Components[i])
+	}
+	return n2
+}
+
+// AppendNumber returns a new name with a NonNegativeInteger component of the given TLV-TYPE appended.
+func (n Name) AppendNumber(t, v uint64) Name {
+	return n.Append(t, encodeNumber(v))
+}
+
+// AppendSegment returns a new name with a segment number appended.
+func (n Name) AppendSegment(seg uint64) Name {
+	return n.AppendNumber(ComponentTypeSegment, seg)
+}
+
+// AppendByteOffset returns a new name with a byte offset appended.
+func (n Name) AppendByteOffset(off uint64) Name {
+	return n.AppendNumber(ComponentTypeByteOffset, off)
+}
+
+// AppendVersion returns a new name with a version appended.
+func (n Name) AppendVersion(v uint64) Name {
+	return n.AppendNumber(ComponentTypeVersion, v)
+}
+
+// timeVersion returns the version of t, which is the number of milliseconds
+// since UNIX epoch as used by certificates.
+func timeVersion(t time.Time) uint64 {
+	return uint64(t.UnixNano() / 1000000)
+}
+
+// AppendTimestamp returns a new name with a timestamp appended.
+//
+// The timestamp is encoded as the number of microseconds since UNIX epoch.
+func (n Name) AppendTimestamp(t time.Time) Name {
+	return n.AppendNumber(ComponentTypeTimestamp, uint64(t.UnixNano()/1000))
+}
+
+// AppendSequenceNumber returns a new name with a sequence number appended.
+func (n Name) AppendSequenceNumber(seq uint64) Name {
+	return n.AppendNumber(ComponentTypeSequenceNumber, seq)
+}
+
+// AppendKeyword returns a new name with a keyword component appended.
+func (n Name) AppendKeyword(keyword lpm.Component) Name {
+	return n.Append(ComponentTypeKeyword, keyword)
+}
+
+// Number returns the NonNegativeInteger value of the last component
+// if it has the given TLV-TYPE.
+func (n *Name) Number(t uint64) (uint64, bool) {
+	l := n.Len()
+	if l == 0 || n.Type(l-1) != t {
+		return 0, false
+	}
+	v, err := decodeNumber(n.Components[l-1])
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Segment returns the segment number of the last component.
+func (n *Name) Segment() (uint64, bool) {
+	return n.Number(ComponentTypeSegment)
+}
+
+// ByteOffset returns the byte offset of the last component.
+func (n *Name) ByteOffset() (uint64, bool) {
+	return n.Number(ComponentTypeByteOffset)
+}
+
+// Version returns the version of the last component.
+func (n *Name) Version() (uint64, bool) {
+	return n.Number(ComponentTypeVersion)
+}
+
+// Timestamp returns the timestamp of the last component.
+func (n *Name) Timestamp() (time.Time, bool) {
+	v, ok := n.Number(ComponentTypeTimestamp)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(v)*1000), true
+}
+
+// SequenceNumber returns the sequence number of the last component.
+func (n *Name) SequenceNumber() (uint64, bool) {
+	return n.Number(ComponentTypeSequenceNumber)
+}
+
+// Keyword returns the value of the last component if it is a keyword.
+func (n *Name) Keyword() (lpm.Component, bool) {
+	l := n.Len()
+	if l == 0 || n.Type(l-1) != ComponentTypeKeyword {
+		return nil, false
+	}
+	return n.Components[l-1], true
+}
+
+// ParametersSHA256 returns ParametersSha256DigestComponent if the name has one.
+func (n *Name) ParametersSHA256() (lpm.Component, bool) {
+	for i := range n.Components {
+		if n.Type(i) == ComponentTypeParametersSHA256Digest {
+			return n.Components[i], true
+		}
+	}
+	return nil, false
+}
+
+// setParametersSHA256 replaces ParametersSha256DigestComponent with
+// the given digest as the last component.
+func (n *Name) setParametersSHA256(digest lpm.Component) {
+	*n = n.withoutParametersSHA256().Append(ComponentTypeParametersSHA256Digest, digest)
+}
+
+// withoutParametersSHA256 returns a name without ParametersSha256DigestComponent.
+func (n Name) withoutParametersSHA256() Name {
+	if _, ok := n.ParametersSHA256(); !ok {
+		return n
+	}
+	var n2 Name
+	for i, c := range n.Components {
+		if t := n.Type(i); t != ComponentTypeParametersSHA256Digest {
+			n2.push(t, c)
+		}
+	}
+	n2.ImplicitDigestSHA256 = n.ImplicitDigestSHA256
+	return n2
+}
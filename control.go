@@ -0,0 +1,113 @@
+package ndn
+
+import "fmt"
+
+// ControlError is returned when the forwarder rejects a command.
+//
+// The response may contain parameters, such as the FaceID of an existing
+// face when faces/create fails with 409.
+type ControlError struct {
+	Module  string
+	Command string
+	CommandResponse
+}
+
+func (err *ControlError) Error() string {
+	return fmt.Sprintf("%s/%s: %d %s", err.Module, err.Command, err.StatusCode, err.StatusText)
+}
+
+// Controller manages the forwarder with signed commands.
+//
+// Each method returns the response of a successful command, which echoes
+// the parameters applied by the forwarder. *ControlError is returned if
+// the status code is not 200.
+//
+// See http://redmine.named-data.net/projects/nfd/wiki/Management.
+type Controller struct {
+	Sender
+	key Key
+}
+
+// NewController creates a controller that signs commands with key.
+func NewController(w Sender, key Key) *Controller {
+	return &Controller{
+		Sender: w,
+		key:    key,
+	}
+}
+
+// Control sends any command to the forwarder.
+func (c *Controller) Control(module, command string, params *Parameters) (*CommandResponse, error) {
+	resp, err := sendControl(c.Sender, module, command, params, c.key)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, &ControlError{
+			Module:          module,
+			Command:         command,
+			CommandResponse: *resp,
+		}
+	}
+	return resp, nil
+}
+
+// FaceCreate creates a face to URI.
+//
+// The FaceID of the new face is returned in the response.
+func (c *Controller) FaceCreate(params *Parameters) (*CommandResponse, error) {
+	return c.Control("faces", "create", params)
+}
+
+// FaceUpdate changes the properties of the face with FaceID.
+//
+// If FaceID is 0, the face that sends the command is updated.
+func (c *Controller) FaceUpdate(params *Parameters) (*CommandResponse, error) {
+	return c.Control("faces", "update", params)
+}
+
+// FaceDestroy destroys the face with FaceID.
+func (c *Controller) FaceDestroy(faceID uint64) (*CommandResponse, error) {
+	return c.Control("faces", "destroy", &Parameters{
+		FaceID: faceID,
+	})
+}
+
+// RIBRegister adds a route of Name.
+//
+// If FaceID is 0, the route points to the face that sends the command.
+func (c *Controller) RIBRegister(params *Parameters) (*CommandResponse, error) {
+	return c.Control("rib", "register", params)
+}
+
+// RIBUnregister removes a route of Name.
+//
+// If FaceID is 0, the route to the face that sends the command is removed.
+func (c *Controller) RIBUnregister(params *Parameters) (*CommandResponse, error) {
+	return c.Control("rib", "unregister", params)
+}
+
+// StrategySet chooses the forwarding strategy for prefix.
+func (c *Controller) StrategySet(prefix, strategy Name) (*CommandResponse, error) {
+	return c.Control("strategy-choice", "set", &Parameters{
+		Name: prefix,
+		Strategy: Strategy{
+			Name: strategy,
+		},
+	})
+}
+
+// StrategyUnset reverts the forwarding strategy for prefix to the one
+// of its parent namespace.
+func (c *Controller) StrategyUnset(prefix Name) (*CommandResponse, error) {
+	return c.Control("strategy-choice", "unset", &Parameters{
+		Name: prefix,
+	})
+}
+
+// CSConfig changes Capacity and Flags of the content store.
+//
+// Only the flags selected by Mask are changed.
+func (c *Controller) CSConfig(params *Parameters) (*CommandResponse, error) {
+	return c.Control("cs", "config", params)
+}
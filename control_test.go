@@ -0,0 +1,99 @@
+package ndn
+
+import (
+	"testing"
+
+	"github.com/go-ndn/tlv"
+)
+
+// serveControl answers commands like the forwarder.
+//
+// Faces with FaceID 256 and above exist.
+func serveControl(t *testing.T, w Sender, recv <-chan *Interest) {
+	nextFaceID := uint64(256)
+	for i := range recv {
+		var params Parameters
+		err := tlv.Unmarshal(i.Name.Components[4], &params, 104)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		resp := &CommandResponse{
+			StatusCode: 200,
+			StatusText: "OK",
+			Parameters: params,
+		}
+		if err := VerifyInterest(ed25519Key, i); err != nil {
+			resp.StatusCode, resp.StatusText = 403, "Unauthorized"
+		}
+		switch string(i.Name.Components[2]) + "/" + string(i.Name.Components[3]) {
+		case "faces/create":
+			resp.Parameters.FaceID = nextFaceID
+			nextFaceID++
+		case "faces/destroy":
+			if params.FaceID < 256 {
+				resp.StatusCode, resp.StatusText = 404, "Face not found"
+			}
+		}
+		b, err := tlv.Marshal(resp, 101)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		w.SendData(&Data{
+			Name:    i.Name,
+			Content: b,
+		})
+	}
+}
+
+func TestController(t *testing.T) {
+	recv := make(chan *Interest)
+	consumer, nfd := NewFacePair(Link{}, nil, recv)
+	defer consumer.Close()
+	defer nfd.Close()
+	go serveControl(t, nfd, recv)
+
+	c := NewController(consumer, ed25519Key)
+	resp, err := c.FaceCreate(&Parameters{
+		URI: "udp4://10.0.0.1:6363",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Parameters.FaceID != 256 || resp.Parameters.URI != "udp4://10.0.0.1:6363" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	_, err = c.RIBRegister(&Parameters{
+		Name:   NewName("/A"),
+		FaceID: resp.Parameters.FaceID,
+		Cost:   10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = c.StrategySet(NewName("/A"), NewName("/localhost/nfd/strategy/multicast"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Parameters.Strategy.Name.String() != "/localhost/nfd/strategy/multicast" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	_, err = c.FaceDestroy(1)
+	if cerr, ok := err.(*ControlError); !ok || cerr.StatusCode != 404 || cerr.Module != "faces" || cerr.Command != "destroy" {
+		t.Fatalf("expect faces/destroy 404, got %v", err)
+	}
+
+	// unauthorized
+	_, err = NewController(consumer, rsaKey).CSConfig(&Parameters{
+		Capacity: 100,
+	})
+	if cerr, ok := err.(*ControlError); !ok || cerr.StatusCode != 403 {
+		t.Fatalf("expect 403, got %v", err)
+	}
+	err = SendControl(consumer, "cs", "config", &Parameters{}, rsaKey)
+	if err != ErrResponseStatus {
+		t.Fatalf("expect %v, got %v", ErrResponseStatus, err)
+	}
+}
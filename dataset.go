@@ -0,0 +1,214 @@
+package ndn
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"github.com/go-ndn/lpm"
+	"github.com/go-ndn/tlv"
+)
+
+// FaceQueryFilter selects faces in FaceQuery.
+//
+// Only non-empty fields are matched.
+type FaceQueryFilter struct {
+	FaceID      uint64
+	URIScheme   string
+	URI         string
+	LocalURI    string
+	Scope       *uint64
+	Persistency *uint64
+	LinkType    *uint64
+}
+
+// MarshalBinary encodes FaceQueryFilter in tlv.
+//
+// FaceQueryFilter needs to implement encoding.BinaryMarshaler
+// because zero Scope, Persistency and LinkType are still present.
+func (f FaceQueryFilter) MarshalBinary() ([]byte, error) {
+	number := func(v *uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	buf := new(bytes.Buffer)
+	w := tlv.NewWriter(buf)
+	for _, field := range []struct {
+		v    interface{}
+		t    uint64
+		omit bool
+	}{
+		{f.FaceID, 105, f.FaceID == 0},
+		{f.URIScheme, 131, f.URIScheme == ""},
+		{f.URI, 114, f.URI == ""},
+		{f.LocalURI, 129, f.LocalURI == ""},
+		{number(f.Scope), 132, f.Scope == nil},
+		{number(f.Persistency), 133, f.Persistency == nil},
+		{number(f.LinkType), 134, f.LinkType == nil},
+	} {
+		if field.omit {
+			continue
+		}
+		err := w.Write(field.v, field.t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes FaceQueryFilter in tlv.
+func (f *FaceQueryFilter) UnmarshalBinary(b []byte) error {
+	*f = FaceQueryFilter{}
+	r := tlv.NewReader(bytes.NewReader(b))
+	for {
+		var err error
+		switch t := r.Peek(); t {
+		case 105:
+			err = r.Read(&f.FaceID, t)
+		case 131:
+			err = r.Read(&f.URIScheme, t)
+		case 114:
+			err = r.Read(&f.URI, t)
+		case 129:
+			err = r.Read(&f.LocalURI, t)
+		case 132:
+			f.Scope = new(uint64)
+			err = r.Read(f.Scope, t)
+		case 133:
+			f.Persistency = new(uint64)
+			err = r.Read(f.Persistency, t)
+		case 134:
+			f.LinkType = new(uint64)
+			err = r.Read(f.LinkType, t)
+		case 0:
+			return nil
+		default:
+			return ErrNotSupported
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fetchDataset retrieves the latest version of a status dataset.
+//
+// See https://redmine.named-data.net/projects/nfd/wiki/StatusDataset.
+func fetchDataset(ctx context.Context, s Sender, name Name) ([]byte, error) {
+	d, err := sendInterest(ctx, s, &Interest{
+		Name:        name,
+		CanBePrefix: true,
+		MustBeFresh: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	l := name.Len()
+	if d.Name.Len() <= l || d.Name.Type(l) != ComponentTypeVersion {
+		return nil, ErrNotSegmented
+	}
+	f := &Fetcher{
+		Sender: s,
+	}
+	r := f.Fetch(ctx, d.Name.Prefix(l+1))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// datasetName creates the name of a status dataset of the forwarder.
+func datasetName(module, dataset string) Name {
+	return NewName("/localhost/nfd").
+		Append(ComponentTypeGeneric, lpm.Component(module)).
+		Append(ComponentTypeGeneric, lpm.Component(dataset))
+}
+
+// FaceList retrieves the status of all faces.
+func FaceList(ctx context.Context, s Sender) ([]FaceStatus, error) {
+	return faceDataset(ctx, s, datasetName("faces", "list"))
+}
+
+// FaceQuery retrieves the status of faces that match filter.
+func FaceQuery(ctx context.Context, s Sender, filter *FaceQueryFilter) ([]FaceStatus, error) {
+	b, err := tlv.Marshal(filter, 150)
+	if err != nil {
+		return nil, err
+	}
+	return faceDataset(ctx, s, datasetName("faces", "query").Append(ComponentTypeGeneric, b))
+}
+
+func faceDataset(ctx context.Context, s Sender, name Name) ([]FaceStatus, error) {
+	b, err := fetchDataset(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	var faces []FaceStatus
+	err = tlv.Unmarshal(b, &faces, 128)
+	if err != nil {
+		return nil, err
+	}
+	return faces, nil
+}
+
+// FIBList retrieves all FIB entries.
+func FIBList(ctx context.Context, s Sender) ([]FIBEntry, error) {
+	b, err := fetchDataset(ctx, s, datasetName("fib", "list"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []FIBEntry
+	err = tlv.Unmarshal(b, &entries, 128)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RIBList retrieves all RIB entries.
+func RIBList(ctx context.Context, s Sender) ([]RIBEntry, error) {
+	b, err := fetchDataset(ctx, s, datasetName("rib", "list"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []RIBEntry
+	err = tlv.Unmarshal(b, &entries, 128)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// StrategyChoiceList retrieves the forwarding strategy of all namespaces.
+func StrategyChoiceList(ctx context.Context, s Sender) ([]StrategyChoice, error) {
+	b, err := fetchDataset(ctx, s, datasetName("strategy-choice", "list"))
+	if err != nil {
+		return nil, err
+	}
+	var choices []StrategyChoice
+	err = tlv.Unmarshal(b, &choices, 128)
+	if err != nil {
+		return nil, err
+	}
+	return choices, nil
+}
+
+// GeneralStatus retrieves the general status of the forwarder.
+func GeneralStatus(ctx context.Context, s Sender) (*ForwarderStatus, error) {
+	b, err := fetchDataset(ctx, s, datasetName("status", "general"))
+	if err != nil {
+		return nil, err
+	}
+	// fields are not wrapped in an outer tlv
+	b, err = tlv.Marshal(b, 128)
+	if err != nil {
+		return nil, err
+	}
+	status := new(ForwarderStatus)
+	err = tlv.Unmarshal(b, status, 128)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,123 @@
+package ndn
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-ndn/tlv"
+)
+
+func TestDataset(t *testing.T) {
+	recv := make(chan *Interest)
+	consumer, nfd := NewFacePair(Link{}, nil, recv)
+	defer consumer.Close()
+	defer nfd.Close()
+
+	p := NewProducer(NewCache(1024), nil, 100)
+	p.FreshnessPeriod = 1000
+	go p.Serve(nfd, recv)
+
+	publish := func(name Name, v interface{}) {
+		buf := new(bytes.Buffer)
+		err := tlv.NewWriter(buf).Write(v, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = p.Publish(name, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	faces := []FaceStatus{
+		{FaceID: 1, URI: "internal://", LocalURI: "internal://", Scope: 1},
+		{FaceID: 256, URI: "udp4://10.0.0.1:6363", LocalURI: "udp4://10.0.0.2:6363", InByte: 12345},
+	}
+	publish(datasetName("faces", "list"), faces)
+	nonLocal := uint64(0)
+	filter := &FaceQueryFilter{
+		Scope: &nonLocal,
+	}
+	b, err := tlv.Marshal(filter, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(datasetName("faces", "query").Append(ComponentTypeGeneric, b), faces[1:])
+
+	fib := []FIBEntry{
+		{
+			Name:    NewName("/A"),
+			NextHop: []NextHopRecord{{FaceID: 256, Cost: 10}, {FaceID: 257, Cost: 20}},
+		},
+	}
+	publish(datasetName("fib", "list"), fib)
+
+	// general status is not wrapped in an outer tlv
+	status := &ForwarderStatus{
+		NFDVersion: "0.7.0",
+		FIBEntry:   1,
+		InInterest: 100,
+	}
+	b, err = tlv.Marshal(status, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Publish(datasetName("status", "general"), bytes.NewReader(b[2:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	gotFaces, err := FaceList(ctx, consumer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(faces, gotFaces) {
+		t.Fatalf("expect %+v, got %+v", faces, gotFaces)
+	}
+	gotFaces, err = FaceQuery(ctx, consumer, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(faces[1:], gotFaces) {
+		t.Fatalf("expect %+v, got %+v", faces[1:], gotFaces)
+	}
+	gotFIB, err := FIBList(ctx, consumer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fib, gotFIB) {
+		t.Fatalf("expect %+v, got %+v", fib, gotFIB)
+	}
+	gotStatus, err := GeneralStatus(ctx, consumer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status, gotStatus) {
+		t.Fatalf("expect %+v, got %+v", status, gotStatus)
+	}
+}
+
+func TestFaceQueryFilter(t *testing.T) {
+	zero, one := uint64(0), uint64(1)
+	for _, want := range []*FaceQueryFilter{
+		{},
+		{URIScheme: "udp4", Scope: &zero},
+		{FaceID: 256, Persistency: &zero, LinkType: &one},
+	} {
+		b, err := tlv.Marshal(want, 150)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := new(FaceQueryFilter)
+		err = tlv.Unmarshal(b, got, 150)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("expect %+v, got %+v", want, got)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package ndn
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/go-ndn/lpm"
+	"github.com/go-ndn/tlv"
+)
+
+// DiskCache is a thread-safe persistent content store.
+//
+// Data packets are appended to a log file in tlv, and an index over names
+// is kept in memory. The index is rebuilt from the log when the content store
+// is opened again, so MustBeFresh is evaluated from the time of opening.
+//
+// Deleted and duplicate data packets remain in the log until Compact is called.
+//
+// Writes to the log are not synced to disk until Sync, Compact or Close is called,
+// so recently added data packets might be lost after a crash.
+type DiskCache struct {
+	index   *cache
+	records map[*Data]diskRecord // indexed data -> location in log
+	file    *os.File
+	path    string
+	end     int64 // size of log
+	mu      sync.RWMutex
+}
+
+type diskRecord struct {
+	offset int64
+	size   int64
+}
+
+// noEviction is a policy that never evicts.
+type noEviction struct{}
+
+func (noEviction) Add(string, *Data) {}
+func (noEviction) Hit(string)        {}
+func (noEviction) Remove(string)     {}
+func (noEviction) Evict() string     { return "" }
+
+// NewDiskCache opens the content store at path, or creates it if it does not exist.
+//
+// A partially written data packet at the end of the log is discarded.
+func NewDiskCache(path string) (*DiskCache, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	c := &DiskCache{
+		index: &cache{
+			entries: make(map[string]*cacheEntry),
+			Policy:  noEviction{},
+			size:    int(^uint(0) >> 1),
+		},
+		records: make(map[*Data]diskRecord),
+		file:    f,
+		path:    path,
+	}
+	err = c.load()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// load replays the log to rebuild the index.
+//
+// The log is truncated only after a partially written element at the end.
+func (c *DiskCache) load() error {
+	r := bufio.NewReader(c.file)
+	for {
+		t, b, err := readRecord(r, math.MaxInt64)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t {
+		case 6:
+			d := new(Data)
+			err = tlv.Unmarshal(b, d, 6)
+			if err != nil {
+				return err
+			}
+			digest := sha256.Sum256(b)
+			c.insert(d, digest[:], c.end, int64(len(b)))
+		case 7:
+			// deleted prefix
+			var prefix Name
+			err = tlv.Unmarshal(b, &prefix, 7)
+			if err != nil {
+				return err
+			}
+			c.removePrefix(prefix)
+		default:
+			return ErrNotSupported
+		}
+		c.end += int64(len(b))
+	}
+	return c.file.Truncate(c.end)
+}
+
+// insert indexes d that is stored in the log.
+//
+// Only fields used by selectors are kept in memory.
+func (c *DiskCache) insert(d *Data, digest lpm.Component, offset, size int64) {
+	ent := &Data{
+		Name:          d.Name,
+		MetaInfo:      d.MetaInfo,
+		SignatureInfo: d.SignatureInfo,
+	}
+	if c.index.add(ent, digest, size) {
+		c.records[ent] = diskRecord{
+			offset: offset,
+			size:   size,
+		}
+	}
+}
+
+func (c *DiskCache) removePrefix(prefix Name) {
+	for _, ent := range c.index.removePrefix(prefix) {
+		delete(c.records, ent)
+	}
+}
+
+// append writes b at the end of the log.
+func (c *DiskCache) append(b []byte) error {
+	_, err := c.file.WriteAt(b, c.end)
+	if err != nil {
+		// discard partial write
+		c.file.Truncate(c.end)
+		return err
+	}
+	c.end += int64(len(b))
+	return nil
+}
+
+// Add stores d in the log.
+//
+// Data packets that fail to be encoded or written are ignored.
+// See Sync for durability.
+func (c *DiskCache) Add(d *Data) {
+	buf := new(bytes.Buffer)
+	err := d.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		return
+	}
+	b := buf.Bytes()
+	digest := sha256.Sum256(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index.contains(d.Name, digest[:]) {
+		return
+	}
+	offset := c.end
+	err = c.append(b)
+	if err != nil {
+		return
+	}
+	c.insert(d, digest[:], offset, int64(len(b)))
+}
+
+// Get finds data packet by interest with the same semantics as NewCache,
+// and reads it from the log.
+func (c *DiskCache) Get(i *Interest) *Data {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ent := c.index.Get(i)
+	if ent == nil {
+		return nil
+	}
+	rec := c.records[ent]
+	d := new(Data)
+	err := d.ReadFrom(tlv.NewReader(io.NewSectionReader(c.file, rec.offset, rec.size)))
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// Delete removes all data packets under prefix.
+func (c *DiskCache) Delete(prefix Name) error {
+	buf := new(bytes.Buffer)
+	err := prefix.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err = c.append(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	c.removePrefix(prefix)
+	return nil
+}
+
+// Len returns the number of data packets.
+func (c *DiskCache) Len() int {
+	return c.index.Len()
+}
+
+// Bytes returns the total encoded size of data packets.
+//
+// The log is larger if it contains deleted or duplicate data packets.
+func (c *DiskCache) Bytes() int64 {
+	return c.index.Bytes()
+}
+
+// Compact rewrites the log so that it only contains data packets in the index.
+//
+// The new log replaces the old one atomically.
+func (c *DiskCache) Compact() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmpPath := c.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	records, end, err := c.copyTo(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, c.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	c.file.Close()
+	c.file = tmp
+	c.records = records
+	c.end = end
+	return nil
+}
+
+// copyTo copies indexed data packets to f in the order of the log.
+func (c *DiskCache) copyTo(f *os.File) (map[*Data]diskRecord, int64, error) {
+	ents := make([]*Data, 0, len(c.records))
+	for ent := range c.records {
+		ents = append(ents, ent)
+	}
+	sort.Slice(ents, func(i, j int) bool {
+		return c.records[ents[i]].offset < c.records[ents[j]].offset
+	})
+
+	w := bufio.NewWriter(f)
+	records := make(map[*Data]diskRecord, len(ents))
+	var end int64
+	for _, ent := range ents {
+		rec := c.records[ent]
+		_, err := io.Copy(w, io.NewSectionReader(c.file, rec.offset, rec.size))
+		if err != nil {
+			return nil, 0, err
+		}
+		records[ent] = diskRecord{
+			offset: end,
+			size:   rec.size,
+		}
+		end += rec.size
+	}
+	return records, end, w.Flush()
+}
+
+// Sync flushes the log to disk.
+func (c *DiskCache) Sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Sync()
+}
+
+// Close flushes the log to disk, and closes it.
+func (c *DiskCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.file.Sync()
+	if err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,117 @@
+package ndn
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ndn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache")
+
+	c, err := NewDiskCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []string{
+		"/A/B",
+		"/A/C",
+		"/A/C",
+		"/D/E",
+	} {
+		c.Add(&Data{
+			Name:    NewName(test),
+			Content: []byte(test),
+		})
+	}
+	err = c.Delete(NewName("/D"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// partially written data packet
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{6, 0xfd, 1})
+	f.Close()
+
+	for step := 0; step < 2; step++ {
+		c, err = NewDiskCache(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Len() != 2 {
+			t.Fatalf("expect 2 data packets, got %d", c.Len())
+		}
+		for _, test := range []struct {
+			in            string
+			want          string
+			childSelector uint64
+		}{
+			{
+				in:   "/A",
+				want: "/A/B",
+			},
+			{
+				in:            "/A",
+				want:          "/A/C",
+				childSelector: 1,
+			},
+			{
+				in: "/D",
+			},
+		} {
+			d := c.Get(&Interest{
+				Name:        NewName(test.in),
+				CanBePrefix: true,
+				Selectors: Selectors{
+					ChildSelector: test.childSelector,
+				},
+			})
+			var got string
+			if d != nil {
+				got = d.Name.String()
+				if !bytes.Equal(d.Content, []byte(got)) {
+					t.Fatalf("expect content %q, got %q", got, d.Content)
+				}
+			}
+			if got != test.want {
+				t.Fatalf("Get(%v) == %v, got %v", test.in, test.want, got)
+			}
+		}
+		if step == 0 {
+			err = c.Compact()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.Size() != c.Bytes() {
+				t.Fatalf("expect log size %d, got %d", c.Bytes(), fi.Size())
+			}
+		}
+		err = c.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ndn
+
+import (
+	"sync"
+
+	"github.com/go-ndn/lpm"
+)
+
+// Handler responds to an incoming interest.
+type Handler interface {
+	ServeNDN(Face, *Interest)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as Handler.
+type HandlerFunc func(Face, *Interest)
+
+// ServeNDN invokes f(w, i).
+func (f HandlerFunc) ServeNDN(w Face, i *Interest) {
+	f(w, i)
+}
+
+// Dispatcher routes incoming interests to handlers by longest prefix match.
+type Dispatcher struct {
+	handlers filterMatcher
+	mu       sync.RWMutex
+
+	face Face
+	key  Key
+}
+
+// NewDispatcher creates a dispatcher for interests received on f.
+//
+// If key is not nil, a route is registered to the forwarder for each handler,
+// and unregistered when the handler is removed.
+func NewDispatcher(f Face, key Key) *Dispatcher {
+	return &Dispatcher{
+		face: f,
+		key:  key,
+	}
+}
+
+// Handle registers the handler for the given prefix.
+//
+// An existing handler for the same prefix is replaced.
+func (d *Dispatcher) Handle(prefix Name, h Handler) error {
+	if d.key != nil {
+		err := SendControl(d.face, "rib", "register", &Parameters{
+			Name: prefix,
+		}, d.key)
+		if err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	d.handlers.Update(prefix.key(), h)
+	d.mu.Unlock()
+	return nil
+}
+
+// HandleFunc registers the handler function for the given prefix.
+func (d *Dispatcher) HandleFunc(prefix Name, f func(Face, *Interest)) error {
+	return d.Handle(prefix, HandlerFunc(f))
+}
+
+// Remove removes the handler for the given prefix.
+func (d *Dispatcher) Remove(prefix Name) error {
+	d.mu.Lock()
+	d.handlers.Delete(prefix.key())
+	d.mu.Unlock()
+	if d.key != nil {
+		return SendControl(d.face, "rib", "unregister", &Parameters{
+			Name: prefix,
+		}, d.key)
+	}
+	return nil
+}
+
+// Serve dispatches interests from recv until it is closed.
+//
+// Each handler is invoked in its own goroutine.
+// Interests without any matching handler are nacked with NackReasonNoRoute.
+func (d *Dispatcher) Serve(recv <-chan *Interest) {
+	for i := range recv {
+		d.mu.RLock()
+		h, ok := d.longestMatch(i.Name.key())
+		d.mu.RUnlock()
+		if !ok {
+			d.face.SendNack(i, NackReasonNoRoute)
+			continue
+		}
+		go h.ServeNDN(d.face, i)
+	}
+}
+
+// longestMatch finds the handler of the longest prefix that has one.
+//
+// Dispatcher mutex must be held.
+func (d *Dispatcher) longestMatch(name []lpm.Component) (Handler, bool) {
+	for l := len(name); l >= 0; l-- {
+		if h, ok := d.handlers.Get(name[:l]); ok {
+			return h, true
+		}
+	}
+	return nil, false
+}
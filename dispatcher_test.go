@@ -0,0 +1,60 @@
+package ndn
+
+import (
+	"net"
+	"testing"
+)
+
+func TestDispatcher(t *testing.T) {
+	c1, c2 := net.Pipe()
+	consumer := NewFace(c1, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewFace(c2, recv)
+	defer producer.Close()
+
+	d := NewDispatcher(producer, nil)
+	for _, prefix := range []string{"/A", "/A/B", "/A/B/C/D", "/C", "/E", "/E/F/G"} {
+		prefix := prefix
+		err := d.HandleFunc(NewName(prefix), func(w Face, i *Interest) {
+			w.SendData(&Data{
+				Name:    i.Name,
+				Content: []byte(prefix),
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := d.Remove(NewName("/C"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go d.Serve(recv)
+
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"/A", "/A"},
+		{"/A/C", "/A"},
+		{"/A/B/C", "/A/B"},
+		{"/A/B/C/D", "/A/B/C/D"},
+		{"/E/F/H", "/E"}, // longest prefix without handler
+		{"/C/D", ""},
+		{"/D", ""},
+	} {
+		d, err := consumer.SendInterest(&Interest{
+			Name: NewName(test.in),
+		})
+		var got string
+		if err == nil {
+			got = string(d.Content)
+		} else if _, ok := err.(*NackError); !ok {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Fatalf("Serve(%v) == %v, got %v", test.in, test.want, got)
+		}
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"math/big"
-
-	"github.com/go-ndn/tlv"
 )
 
 // ECDSAKey implements Key.
@@ -43,7 +41,7 @@ type ecdsaSignature struct {
 
 // Sign creates signature.
 func (key *ECDSAKey) Sign(v interface{}) ([]byte, error) {
-	digest, err := tlv.Hash(sha256.New, v)
+	digest, err := hashSigned(sha256.New, v)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +55,7 @@ func (key *ECDSAKey) Sign(v interface{}) ([]byte, error) {
 
 // Verify checks signature.
 func (key *ECDSAKey) Verify(v interface{}, signature []byte) error {
-	digest, err := tlv.Hash(sha256.New, v)
+	digest, err := hashSigned(sha256.New, v)
 	if err != nil {
 		return err
 	}
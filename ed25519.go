@@ -0,0 +1,88 @@
+package ndn
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"hash"
+)
+
+// Ed25519Key implements Key.
+type Ed25519Key struct {
+	Name
+	// PrivateKey is nil if the key is created from a certificate.
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// Locator returns public key locator.
+func (key *Ed25519Key) Locator() Name {
+	return key.Name
+}
+
+// Private encodes private key.
+func (key *Ed25519Key) Private() ([]byte, error) {
+	if len(key.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, ErrNotSupported
+	}
+	return x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+}
+
+// Public encodes public key.
+func (key *Ed25519Key) Public() ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(key.PublicKey)
+}
+
+// SignatureType returns signature type generated from the key.
+func (key *Ed25519Key) SignatureType() uint64 {
+	return SignatureTypeEd25519
+}
+
+// signedBuffer collects the signed portion of a packet.
+//
+// Ed25519 signs the message itself instead of its digest,
+// so signedBuffer implements hash.Hash to be used with hashSigned.
+type signedBuffer struct {
+	bytes.Buffer
+}
+
+func newSignedBuffer() hash.Hash {
+	return new(signedBuffer)
+}
+
+func (b *signedBuffer) Sum(p []byte) []byte {
+	return append(p, b.Bytes()...)
+}
+
+func (b *signedBuffer) Size() int {
+	return b.Len()
+}
+
+func (b *signedBuffer) BlockSize() int {
+	return 1
+}
+
+// Sign creates signature.
+func (key *Ed25519Key) Sign(v interface{}) ([]byte, error) {
+	if len(key.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, ErrNotSupported
+	}
+	msg, err := hashSigned(newSignedBuffer, v)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(key.PrivateKey, msg), nil
+}
+
+// Verify checks signature.
+func (key *Ed25519Key) Verify(v interface{}, signature []byte) error {
+	msg, err := hashSigned(newSignedBuffer, v)
+	if err != nil {
+		return err
+	}
+	if len(key.PublicKey) != ed25519.PublicKeySize ||
+		!ed25519.Verify(key.PublicKey, msg, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package ndn
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"net"
 	"reflect"
 	"sync"
@@ -10,6 +14,11 @@ import (
 	"github.com/go-ndn/tlv"
 )
 
+// Errors introduced by Face.
+var (
+	ErrFaceClosed = errors.New("face closed")
+)
+
 // Sender sends interest and data packets.
 // This is the minimum abstraction for NDN nodes.
 type Sender interface {
@@ -18,16 +27,31 @@ type Sender interface {
 }
 
 // Face implements Sender.
+//
+// SendInterestContext is like SendInterest, but the pending interest is
+// removed as soon as ctx is done, and ctx.Err() is returned.
+//
+// SendNack rejects an incoming interest with NackReason.
+//
+// Done is closed when the face is closed, or its transport fails.
+// Err returns the terminal error after Done is closed:
+// ErrFaceClosed if Close is called, io.EOF if the remote side closes,
+// or the transport or decoding error.
+// Pending interests fail with the same error.
 type Face interface {
 	Sender
+	SendInterestContext(context.Context, *Interest) (*Data, error)
+	SendNack(*Interest, uint64) error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
 	Close() error
+	Done() <-chan struct{}
+	Err() error
 }
 
 type face struct {
 	net.Conn
-	tlv.Reader // read
+	r *bufio.Reader // read
 
 	tlv.Writer            // write
 	wm         sync.Mutex // writer mutex
@@ -36,11 +60,15 @@ type face struct {
 	pitm       sync.Mutex // pit mutex
 
 	recv chan<- *Interest
+
+	done chan struct{}
+	err  error // terminal error; pit mutex must be held
 }
 
 type pitEntry struct {
-	*Selectors
+	*Interest
 	timer *time.Timer
+	err   chan<- error
 }
 
 // NewFace creates a face from net.Conn.
@@ -48,35 +76,25 @@ type pitEntry struct {
 // recv is the incoming interest queue.
 // If it is nil, incoming interests will be ignored.
 // Otherwise, this queue must be handled before it is full.
+// Malformed packets are dropped, but a packet larger than 65535 bytes
+// fails the face with ErrPacketTooLarge.
 func NewFace(transport net.Conn, recv chan<- *Interest) Face {
 	f := &face{
 		Conn:   transport,
-		Reader: tlv.NewReader(transport),
+		r:      bufio.NewReader(transport),
 		Writer: tlv.NewWriter(transport),
 		recv:   recv,
+		done:   make(chan struct{}),
 	}
 	go func() {
 		for {
-			switch f.Peek() {
-			case 5:
-				i := new(Interest)
-				err := i.ReadFrom(f.Reader)
-				if err != nil {
-					goto IDLE
-				}
-				f.recvInterest(i)
-			case 6:
-				d := new(Data)
-				err := d.ReadFrom(f.Reader)
-				if err != nil {
-					goto IDLE
-				}
-				f.recvData(d)
-			default:
-				goto IDLE
+			t, b, err := readRecord(f.r, maxPacketSize)
+			if err != nil {
+				f.fail(err)
+				break
 			}
+			f.recvPacket(t, b)
 		}
-	IDLE:
 		if f.recv != nil {
 			close(f.recv)
 		}
@@ -84,15 +102,78 @@ func NewFace(transport net.Conn, recv chan<- *Interest) Face {
 	return f
 }
 
+// fail records the terminal error, and fails all pending interests.
+//
+// Only the first error is recorded.
+func (f *face) fail(err error) {
+	f.pitm.Lock()
+	defer f.pitm.Unlock()
+	select {
+	case <-f.done:
+		return
+	default:
+	}
+	f.err = err
+	close(f.done)
+	f.Visit(func(_ []lpm.Component, m map[chan<- *Data]pitEntry) (map[chan<- *Data]pitEntry, bool) {
+		for ch, e := range m {
+			e.err <- err
+			close(ch)
+			e.timer.Stop()
+		}
+		return nil, true
+	})
+}
+
+func (f *face) Close() error {
+	f.fail(ErrFaceClosed)
+	return f.Conn.Close()
+}
+
+func (f *face) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *face) Err() error {
+	f.pitm.Lock()
+	defer f.pitm.Unlock()
+	return f.err
+}
+
 func (f *face) SendData(d *Data) error {
 	f.wm.Lock()
 	defer f.wm.Unlock()
 	return d.WriteTo(f.Writer)
 }
 
+func (f *face) SendNack(i *Interest, reason uint64) error {
+	buf := new(bytes.Buffer)
+	err := i.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		return err
+	}
+	f.wm.Lock()
+	defer f.wm.Unlock()
+	return (&LpPacket{
+		Nack:     &Nack{Reason: reason},
+		Fragment: buf.Bytes(),
+	}).WriteTo(f.Writer)
+}
+
 func (f *face) SendInterest(i *Interest) (*Data, error) {
-	var err error
+	return f.SendInterestContext(context.Background(), i)
+}
+
+func (f *face) SendInterestContext(ctx context.Context, i *Interest) (*Data, error) {
+	// name must be final before it is used as pit key,
+	// and nonce is used to match nack.
+	err := i.updateParametersDigest()
+	if err != nil {
+		return nil, err
+	}
+	i.updateNonce()
 	ch := make(chan *Data, 1)
+	errCh := make(chan error, 1)
 
 	lifeTime := 4 * time.Second
 	if i.LifeTime != 0 {
@@ -101,64 +182,114 @@ func (f *face) SendInterest(i *Interest) (*Data, error) {
 	timer := time.AfterFunc(lifeTime, func() {
 		f.pitm.Lock()
 		defer f.pitm.Unlock()
-		m, ok := f.Get(i.Name.Components)
-		if !ok {
-			return
-		}
-		if _, ok := m[ch]; !ok {
-			return
-		}
-		close(ch)
-		delete(m, ch)
-		if len(m) == 0 {
-			f.Delete(i.Name.Components)
+		if f.removePending(i.Name.key(), ch) {
+			close(ch)
 		}
 	})
 
-	err = func() error {
-		f.pitm.Lock()
-		defer f.pitm.Unlock()
-		m, ok := f.Get(i.Name.Components)
-		if !ok {
-			m = make(map[chan<- *Data]pitEntry)
-			f.Update(i.Name.Components, m)
-		}
-		var found bool
-		for _, e := range m {
-			if reflect.DeepEqual(e.Selectors, &i.Selectors) {
-				found = true
-				break
-			}
+	var found bool
+	f.pitm.Lock()
+	select {
+	case <-f.done:
+		err = f.err
+		f.pitm.Unlock()
+		timer.Stop()
+		return nil, err
+	default:
+	}
+	key := i.Name.key()
+	m, ok := f.Get(key)
+	if !ok {
+		m = make(map[chan<- *Data]pitEntry)
+		f.Update(key, m)
+	}
+	for _, e := range m {
+		if sameSelection(e.Interest, i) {
+			found = true
+			break
 		}
-		if !found {
-			f.wm.Lock()
-			defer f.wm.Unlock()
-			err = i.WriteTo(f.Writer)
-			if err != nil {
-				return err
+	}
+	m[ch] = pitEntry{
+		Interest: i,
+		timer:    timer,
+		err:      errCh,
+	}
+	f.pitm.Unlock()
+
+	// pit mutex is not held while writing, so that
+	// incoming data can be processed at the same time.
+	if !found {
+		f.wm.Lock()
+		err = i.WriteTo(f.Writer)
+		f.wm.Unlock()
+		if err != nil {
+			f.pitm.Lock()
+			removed := f.removePending(i.Name.key(), ch)
+			f.pitm.Unlock()
+			if removed {
+				timer.Stop()
+				return nil, err
 			}
 		}
-		m[ch] = pitEntry{
-			Selectors: &i.Selectors,
-			timer:     timer,
+	}
+
+	var d *Data
+	select {
+	case d, ok = <-ch:
+	case <-ctx.Done():
+		f.pitm.Lock()
+		removed := f.removePending(i.Name.key(), ch)
+		f.pitm.Unlock()
+		if removed {
+			timer.Stop()
+			return nil, ctx.Err()
 		}
-		return nil
-	}()
-	if err != nil {
-		return nil, err
+		// the pending interest has just been resolved
+		d, ok = <-ch
 	}
-	d, ok := <-ch
 	if !ok {
-		return nil, ErrTimeout
+		select {
+		case err = <-errCh:
+			return nil, err
+		default:
+			return nil, ErrTimeout
+		}
 	}
 	return d, nil
 }
 
+// removePending removes a pending interest from pit.
+// It returns false if the interest is no longer pending.
+//
+// pit mutex must be held.
+func (f *face) removePending(name []lpm.Component, ch chan<- *Data) bool {
+	m, ok := f.Get(name)
+	if !ok {
+		return false
+	}
+	if _, ok := m[ch]; !ok {
+		return false
+	}
+	delete(m, ch)
+	if len(m) == 0 {
+		f.Delete(name)
+	}
+	return true
+}
+
+// sameSelection checks whether two interests select the same data,
+// so that they can be aggregated.
+func sameSelection(i, i2 *Interest) bool {
+	return i.CanBePrefix == i2.CanBePrefix &&
+		i.MustBeFresh == i2.MustBeFresh &&
+		reflect.DeepEqual(i.Selectors, i2.Selectors)
+}
+
 func (f *face) recvData(d *Data) {
 	f.pitm.Lock()
-	f.UpdateAll(d.Name.Components, func(name []lpm.Component, m map[chan<- *Data]pitEntry) (map[chan<- *Data]pitEntry, bool) {
+	f.UpdateAll(d.Name.key(), func(_ []lpm.Component, m map[chan<- *Data]pitEntry) (map[chan<- *Data]pitEntry, bool) {
 		for ch, e := range m {
-			if !e.Match(d, len(name)) {
+			if !e.Match(d) {
 				continue
 			}
 			ch <- d
@@ -174,6 +305,97 @@ func (f *face) recvData(d *Data) {
 	f.pitm.Unlock()
 }
 
+// recvNack fails the pending interest that has the same nonce,
+// and others aggregated with it.
+func (f *face) recvNack(i *Interest, reason uint64) {
+	f.pitm.Lock()
+	defer f.pitm.Unlock()
+	key := i.Name.key()
+	m, ok := f.Get(key)
+	if !ok {
+		return
+	}
+	var sent *Interest
+	for _, e := range m {
+		if bytes.Equal(e.Nonce, i.Nonce) {
+			sent = e.Interest
+			break
+		}
+	}
+	if sent == nil {
+		return
+	}
+	for ch, e := range m {
+		if !sameSelection(e.Interest, sent) {
+			continue
+		}
+		e.err <- &NackError{Reason: reason}
+		close(ch)
+		e.timer.Stop()
+		delete(m, ch)
+	}
+	if len(m) == 0 {
+		f.Delete(key)
+	}
+}
+
+// recvPacket handles one packet in tlv.
+//
+// A malformed packet is dropped, and a packet of unknown type is skipped.
+func (f *face) recvPacket(t uint64, b []byte) {
+	r := tlv.NewReader(bytes.NewReader(b))
+	switch t {
+	case 5:
+		i := new(Interest)
+		if i.ReadFrom(r) == nil {
+			f.recvInterest(i)
+		}
+	case 6:
+		d := new(Data)
+		if d.ReadFrom(r) == nil {
+			f.recvData(d)
+		}
+	case 100:
+		p := new(LpPacket)
+		if p.ReadFrom(r) == nil {
+			f.recvLpPacket(p)
+		}
+	}
+}
+
+// recvLpPacket handles an unfragmented link protocol packet.
+//
+// Malformed fragments are dropped.
+func (f *face) recvLpPacket(p *LpPacket) {
+	if len(p.Fragment) == 0 || p.FragCount > 1 {
+		return
+	}
+	r := tlv.NewReader(bytes.NewReader(p.Fragment))
+	switch r.Peek() {
+	case 5:
+		i := new(Interest)
+		err := i.ReadFrom(r)
+		if err != nil {
+			return
+		}
+		if p.Nack != nil {
+			f.recvNack(i, p.Nack.Reason)
+		} else {
+			f.recvInterest(i)
+		}
+	case 6:
+		if p.Nack != nil {
+			return
+		}
+		d := new(Data)
+		err := d.ReadFrom(r)
+		if err != nil {
+			return
+		}
+		f.recvData(d)
+	}
+}
+
 func (f *face) recvInterest(i *Interest) {
 	if f.recv != nil {
 		f.recv <- i
@@ -2,13 +2,16 @@ package ndn
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
+	"net"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-ndn/packet"
+	"github.com/go-ndn/tlv"
 )
 
 type testFace struct {
@@ -109,6 +112,182 @@ func TestProducer(t *testing.T) {
 	}
 }
 
+func TestNack(t *testing.T) {
+	c1, c2 := net.Pipe()
+	consumer := NewFace(c1, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewFace(c2, recv)
+	defer producer.Close()
+	go func() {
+		for i := range recv {
+			producer.SendNack(i, NackReasonNoRoute)
+		}
+	}()
+
+	_, err := consumer.SendInterest(&Interest{
+		Name: NewName("/A"),
+	})
+	nack, ok := err.(*NackError)
+	if !ok || nack.Reason != NackReasonNoRoute {
+		t.Fatalf("expect NoRoute nack, got %v", err)
+	}
+
+	// nack of another nonce and packet with unknown header are dropped
+	c3, c4 := net.Pipe()
+	consumer = NewFace(c3, nil)
+	defer consumer.Close()
+	go func() {
+		r := tlv.NewReader(c4)
+		w := tlv.NewWriter(c4)
+		i := new(Interest)
+		err := i.ReadFrom(r)
+		if err != nil {
+			return
+		}
+		other := *i
+		other.Nonce = []byte{^i.Nonce[0], 0, 0, 0}
+		w.Write([]byte{90, 1, 1}, 100)
+		for _, test := range []struct {
+			*Interest
+			reason uint64
+		}{
+			{&other, NackReasonDuplicate},
+			{i, NackReasonNoRoute},
+		} {
+			buf := new(bytes.Buffer)
+			test.WriteTo(tlv.NewWriter(buf))
+			(&LpPacket{
+				Nack:     &Nack{Reason: test.reason},
+				Fragment: buf.Bytes(),
+			}).WriteTo(w)
+		}
+	}()
+	_, err = consumer.SendInterest(&Interest{
+		Name: NewName("/A"),
+	})
+	nack, ok = err.(*NackError)
+	if !ok || nack.Reason != NackReasonNoRoute {
+		t.Fatalf("expect NoRoute nack, got %v", err)
+	}
+}
+
+func TestSendInterestContext(t *testing.T) {
+	c1, c2 := net.Pipe()
+	consumer := NewFace(c1, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewFace(c2, recv)
+	defer producer.Close()
+	go func() {
+		for range recv {
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := consumer.SendInterestContext(ctx, &Interest{
+		Name: NewName("/A"),
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	f := consumer.(*face)
+	f.pitm.Lock()
+	defer f.pitm.Unlock()
+	if !f.pitMatcher.Empty() {
+		t.Fatal("pending interest should be removed")
+	}
+}
+
+func TestFaceError(t *testing.T) {
+	c1, c2 := net.Pipe()
+	consumer := NewFace(c1, nil)
+	defer consumer.Close()
+	r := tlv.NewReader(c2)
+	w := tlv.NewWriter(c2)
+
+	// unknown packet is skipped
+	go func() {
+		i := new(Interest)
+		err := i.ReadFrom(r)
+		if err != nil {
+			return
+		}
+		w.Write([]byte{1, 2, 3}, 200)
+		(&Data{Name: i.Name}).WriteTo(w)
+	}()
+	_, err := consumer.SendInterest(&Interest{
+		Name: NewName("/A"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// malformed packets are dropped
+	go func() {
+		i := new(Interest)
+		err := i.ReadFrom(r)
+		if err != nil {
+			return
+		}
+		w.Write([]byte{7, 10, 8, 1}, 5)
+		w.Write([]byte{7, 10, 8, 1}, 6)
+		w.Write([]byte{81, 1, 1}, 100)
+		(&Data{Name: i.Name}).WriteTo(w)
+	}()
+	_, err = consumer.SendInterest(&Interest{
+		Name: NewName("/A/1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pending interest fails after remote close
+	go func() {
+		i := new(Interest)
+		i.ReadFrom(r)
+		c2.Close()
+	}()
+	start := time.Now()
+	_, err = consumer.SendInterest(&Interest{
+		Name: NewName("/B"),
+	})
+	if err == nil || err == ErrTimeout || time.Since(start) > time.Second {
+		t.Fatalf("expect transport error, got %v", err)
+	}
+	<-consumer.Done()
+	if consumer.Err() != err {
+		t.Fatalf("expect %v, got %v", err, consumer.Err())
+	}
+
+	// packet too large
+	c1, c2 = net.Pipe()
+	f := NewFace(c1, nil)
+	go c2.Write([]byte{6, 0xfe, 0x7f, 0xff, 0xff, 0xff})
+	<-f.Done()
+	if f.Err() != ErrPacketTooLarge {
+		t.Fatalf("expect %v, got %v", ErrPacketTooLarge, f.Err())
+	}
+	c2.Close()
+
+	// local close
+	c1, c2 = net.Pipe()
+	defer c2.Close()
+	f = NewFace(c1, nil)
+	f.Close()
+	<-f.Done()
+	if f.Err() != ErrFaceClosed {
+		t.Fatalf("expect %v, got %v", ErrFaceClosed, f.Err())
+	}
+	_, err = f.SendInterest(&Interest{
+		Name: NewName("/A"),
+	})
+	if err != ErrFaceClosed {
+		t.Fatalf("expect %v, got %v", ErrFaceClosed, err)
+	}
+}
+
 func BenchmarkBurstyForward(b *testing.B) {
 	names := make([]string, 64)
 	consumers := make([]*testFace, len(names))
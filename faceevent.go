@@ -0,0 +1,134 @@
+package ndn
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+// FaceEventKind specifies the kind of FaceEventNotification.
+const (
+	FaceEventCreated   uint64 = 1
+	FaceEventDestroyed        = 2
+	FaceEventUp               = 3
+	FaceEventDown             = 4
+)
+
+// FaceEventNotification is published when a face is created, destroyed,
+// or its state is changed.
+type FaceEventNotification struct {
+	Kind        uint64 `tlv:"193"`
+	FaceID      uint64 `tlv:"105"`
+	URI         string `tlv:"114"`
+	LocalURI    string `tlv:"129"`
+	Scope       uint64 `tlv:"132"`
+	Persistency uint64 `tlv:"133"`
+	LinkType    uint64 `tlv:"134"`
+	Flags       uint64 `tlv:"108"`
+}
+
+// FaceEvent is a notification received by FaceEventSubscriber.
+type FaceEvent struct {
+	FaceEventNotification
+	SequenceNumber uint64
+	// Gap is true if some notifications before this one are missed,
+	// so faces should be listed again with FaceList.
+	Gap bool
+}
+
+// FaceEventSubscriber follows the face event notification stream of
+// the forwarder.
+//
+// Notifications are named /localhost/nfd/faces/events/<seq>. The subscriber
+// starts from the latest notification, and then requests the next sequence
+// number. After timeout, it resyncs to the latest notification again.
+//
+// See https://redmine.named-data.net/projects/nfd/wiki/Notification.
+type FaceEventSubscriber struct {
+	Sender
+	// LifeTime is the interest lifetime in milliseconds.
+	LifeTime uint64
+	// RetryInterval is the delay before retrying after an error other than
+	// timeout. If it is 0, it is 1 second.
+	RetryInterval time.Duration
+}
+
+// Subscribe delivers face events until ctx is done.
+//
+// The returned channel is closed when the subscription stops.
+func (sub *FaceEventSubscriber) Subscribe(ctx context.Context) <-chan *FaceEvent {
+	ch := make(chan *FaceEvent)
+	go func() {
+		defer close(ch)
+		sub.subscribe(ctx, ch)
+	}()
+	return ch
+}
+
+func (sub *FaceEventSubscriber) subscribe(ctx context.Context, ch chan<- *FaceEvent) {
+	prefix := datasetName("faces", "events")
+	retryInterval := sub.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = time.Second
+	}
+	var (
+		last   uint64
+		seen   bool // last is valid
+		synced bool // next notification is requested by sequence number
+	)
+	for ctx.Err() == nil {
+		i := &Interest{
+			Name:        prefix,
+			MustBeFresh: true,
+			LifeTime:    sub.LifeTime,
+		}
+		if synced {
+			i.Name = prefix.AppendSequenceNumber(last + 1)
+		} else {
+			i.CanBePrefix = true
+		}
+		d, err := sendInterest(ctx, sub.Sender, i)
+		switch err {
+		case nil:
+		case ErrTimeout:
+			synced = false
+			continue
+		default:
+			synced = false
+			select {
+			case <-time.After(retryInterval):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		seq, ok := d.Name.SequenceNumber()
+		if !ok || d.Name.Len() != prefix.Len()+1 {
+			synced = false
+			continue
+		}
+		if seen && seq <= last {
+			// already delivered
+			synced = true
+			continue
+		}
+		ev := &FaceEvent{
+			SequenceNumber: seq,
+			Gap:            seen && seq != last+1,
+		}
+		err = tlv.Unmarshal(d.Content, &ev.FaceEventNotification, 192)
+		if err != nil {
+			// skip malformed notification
+			ev = nil
+		}
+		last, seen, synced = seq, true, true
+		if ev == nil {
+			continue
+		}
+		select {
+		case ch <- ev:
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ndn
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+// notificationStream keeps only the latest notification like the forwarder
+// that forgets old ones.
+type notificationStream struct {
+	sync.Mutex
+	latest *Data
+}
+
+func (s *notificationStream) publish(t *testing.T, seq uint64, n *FaceEventNotification) {
+	b, err := tlv.Marshal(n, 192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Lock()
+	s.latest = &Data{
+		Name:    datasetName("faces", "events").AppendSequenceNumber(seq),
+		Content: b,
+	}
+	s.Unlock()
+}
+
+func (s *notificationStream) serve(w Sender, recv <-chan *Interest) {
+	for i := range recv {
+		s.Lock()
+		d := s.latest
+		s.Unlock()
+		if d == nil || d.Name.Len() < i.Name.Len() ||
+			d.Name.Prefix(i.Name.Len()).String() != i.Name.String() || !i.Match(d) {
+			continue
+		}
+		w.SendData(d)
+	}
+}
+
+func TestFaceEventSubscriber(t *testing.T) {
+	recv := make(chan *Interest)
+	consumer, nfd := NewFacePair(Link{}, nil, recv)
+	defer consumer.Close()
+	defer nfd.Close()
+	stream := new(notificationStream)
+	go stream.serve(nfd, recv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &FaceEventSubscriber{
+		Sender:   consumer,
+		LifeTime: 50,
+	}
+	events := sub.Subscribe(ctx)
+
+	for _, test := range []struct {
+		seq uint64
+		FaceEventNotification
+		gap bool
+	}{
+		{seq: 1, FaceEventNotification: FaceEventNotification{Kind: FaceEventCreated, FaceID: 256, URI: "udp4://10.0.0.1:6363"}},
+		{seq: 2, FaceEventNotification: FaceEventNotification{Kind: FaceEventDown, FaceID: 256}},
+		// notification 3 is missed
+		{seq: 4, FaceEventNotification: FaceEventNotification{Kind: FaceEventDestroyed, FaceID: 256}, gap: true},
+	} {
+		stream.publish(t, test.seq, &test.FaceEventNotification)
+		var ev *FaceEvent
+		select {
+		case ev = <-events:
+		case <-time.After(time.Second):
+			t.Fatalf("expect notification %d", test.seq)
+		}
+		if ev.SequenceNumber != test.seq || ev.FaceEventNotification != test.FaceEventNotification || ev.Gap != test.gap {
+			t.Fatalf("expect %d %+v (gap %v), got %d %+v (gap %v)",
+				test.seq, test.FaceEventNotification, test.gap, ev.SequenceNumber, ev.FaceEventNotification, ev.Gap)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
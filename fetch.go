@@ -0,0 +1,329 @@
+package ndn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/go-ndn/lpm"
+	"github.com/go-ndn/tlv"
+)
+
+// Errors introduced by Fetcher.
+var (
+	ErrNotSegmented = errors.New("not a segmented object")
+)
+
+// ContentTypeNack is the ContentType of an application-level nack,
+// which a producer sends when the requested data does not exist.
+const ContentTypeNack = 3
+
+// metadataKeyword is the keyword component of realtime data retrieval.
+//
+// See https://redmine.named-data.net/projects/ndn-tlv/wiki/RDR.
+var metadataKeyword = lpm.Component("metadata")
+
+// contextSender is implemented by Face.
+type contextSender interface {
+	SendInterestContext(context.Context, *Interest) (*Data, error)
+}
+
+// sendInterest invokes SendInterestContext if the sender supports it.
+func sendInterest(ctx context.Context, s Sender, i *Interest) (*Data, error) {
+	if cs, ok := s.(contextSender); ok {
+		return cs.SendInterestContext(ctx, i)
+	}
+	return s.SendInterest(i)
+}
+
+// Fetcher retrieves a segmented object with pipelined interests.
+//
+// The object is named /<prefix>/<version>/<segment>, and the last segment
+// is announced by FinalBlockID. If FinalBlockID is never announced,
+// the object ends before the first segment that is nacked
+// or answered with ContentTypeNack.
+type Fetcher struct {
+	Sender
+	// NewWindow creates congestion control for each object.
+	// If it is nil, NewAIMD is used.
+	NewWindow func() CongestionWindow
+	// MaxRetries is the number of retransmissions of each segment
+	// after timeout or nack.
+	MaxRetries int
+	// LifeTime is the interest lifetime in milliseconds.
+	LifeTime uint64
+}
+
+// Fetch retrieves the latest version of the object under name.
+//
+// If name already ends with a version, version discovery is skipped.
+// Otherwise, the version is discovered with RDR metadata, or from any data
+// under name if metadata is not available.
+//
+// The returned reader must be closed to stop fetching.
+func (f *Fetcher) Fetch(ctx context.Context, name Name) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(f.fetch(ctx, name, w))
+		cancel()
+	}()
+	return &fetchReader{
+		PipeReader: r,
+		cancel:     cancel,
+	}
+}
+
+type fetchReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *fetchReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// discover finds the versioned prefix of name.
+//
+// The first segment is returned too if it is retrieved during discovery.
+func (f *Fetcher) discover(ctx context.Context, name Name) (Name, *Data, error) {
+	if _, ok := name.Version(); ok {
+		return name, nil, nil
+	}
+	d, err := sendInterest(ctx, f.Sender, &Interest{
+		Name:        name.AppendKeyword(metadataKeyword),
+		CanBePrefix: true,
+		MustBeFresh: true,
+		LifeTime:    f.LifeTime,
+	})
+	if err == nil {
+		var versioned Name
+		if tlv.Unmarshal(d.Content, &versioned, 7) == nil && isVersionOf(versioned, name) {
+			return versioned, nil, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return Name{}, nil, ctx.Err()
+	}
+
+	d, err = sendInterest(ctx, f.Sender, &Interest{
+		Name:        name,
+		CanBePrefix: true,
+		MustBeFresh: true,
+		LifeTime:    f.LifeTime,
+	})
+	if err != nil {
+		return Name{}, nil, err
+	}
+	l := name.Len()
+	if d.Name.Len() <= l {
+		return Name{}, nil, ErrNotSegmented
+	}
+	switch d.Name.Type(l) {
+	case ComponentTypeVersion:
+		versioned := d.Name.Prefix(l + 1)
+		if seg, ok := d.Name.Segment(); ok && seg == 0 && d.Name.Len() == l+2 {
+			return versioned, d, nil
+		}
+		return versioned, nil, nil
+	case ComponentTypeSegment:
+		// unversioned object
+		if seg, ok := d.Name.Segment(); ok && seg == 0 && d.Name.Len() == l+1 {
+			return name, d, nil
+		}
+		return name, nil, nil
+	default:
+		return Name{}, nil, ErrNotSegmented
+	}
+}
+
+// isVersionOf checks whether versioned is name followed by a version.
+func isVersionOf(versioned, name Name) bool {
+	if _, ok := versioned.Version(); !ok || versioned.Len() != name.Len()+1 {
+		return false
+	}
+	prefix := versioned.Prefix(name.Len())
+	return prefix.Compare(name) == 0
+}
+
+// isFinalSegment checks whether FinalBlockID of d is the value of its own
+// last component, even if FinalBlockID is not typed as a segment.
+func isFinalSegment(d *Data) bool {
+	l := d.Name.Len()
+	id := d.MetaInfo.FinalBlockID
+	return l != 0 && len(id.Component) != 0 && bytes.Equal(id.Component, d.Name.Components[l-1])
+}
+
+type fetchResult struct {
+	seg  uint64
+	d    *Data
+	err  error
+	sent time.Time
+}
+
+func (f *Fetcher) fetch(ctx context.Context, name Name, w io.Writer) error {
+	prefix, first, err := f.discover(ctx, name)
+	if err != nil {
+		return err
+	}
+	var window CongestionWindow
+	if f.NewWindow != nil {
+		window = f.NewWindow()
+	} else {
+		window = NewAIMD()
+	}
+
+	var (
+		next         uint64 // next segment that is never requested
+		deliver      uint64 // next segment to write
+		final        uint64
+		finalKnown   bool
+		announced    bool // whether final is announced by FinalBlockID
+		inflight     int
+		retx         []uint64
+		retries      = make(map[uint64]int)
+		received     = make(map[uint64]*Data)
+		lastDecrease time.Time
+		results      = make(chan fetchResult)
+	)
+	send := func(seg uint64) {
+		inflight++
+		sent := time.Now()
+		i := &Interest{
+			Name:     prefix.AppendSegment(seg),
+			LifeTime: f.LifeTime,
+		}
+		go func() {
+			d, err := sendInterest(ctx, f.Sender, i)
+			select {
+			case results <- fetchResult{seg: seg, d: d, err: err, sent: sent}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	// pastEnd checks whether seg does not exist because the object ends before it.
+	// If it does, the previous segment becomes the last one.
+	pastEnd := func(seg uint64) bool {
+		if seg == 0 || announced {
+			return false
+		}
+		for s := range received {
+			if s > seg {
+				return false
+			}
+		}
+		if !finalKnown || seg-1 < final {
+			final, finalKnown = seg-1, true
+		}
+		return true
+	}
+	recv := func(seg uint64, d *Data) error {
+		if id, ok := d.MetaInfo.FinalBlockID.Segment(); ok {
+			final, finalKnown, announced = id, true, true
+		} else if isFinalSegment(d) {
+			final, finalKnown, announced = seg, true, true
+		}
+		if seg >= deliver {
+			received[seg] = d
+		}
+		for {
+			d, ok := received[deliver]
+			if !ok {
+				return nil
+			}
+			delete(received, deliver)
+			deliver++
+			_, err := w.Write(d.Content)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if first != nil {
+		next = 1
+		err = recv(0, first)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		if finalKnown && deliver > final {
+			return nil
+		}
+		for inflight < window.Size() {
+			if len(retx) > 0 {
+				seg := retx[0]
+				retx = retx[1:]
+				if finalKnown && seg > final {
+					continue
+				}
+				send(seg)
+				continue
+			}
+			if finalKnown && next > final {
+				break
+			}
+			send(next)
+			next++
+		}
+		if inflight == 0 {
+			// nothing can be requested
+			return io.ErrUnexpectedEOF
+		}
+
+		var res fetchResult
+		select {
+		case res = <-results:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		inflight--
+		if finalKnown && res.seg > final {
+			continue
+		}
+		if res.err == nil && res.d.MetaInfo.ContentType == ContentTypeNack {
+			if !pastEnd(res.seg) {
+				return io.ErrUnexpectedEOF
+			}
+			continue
+		}
+		if res.err == nil {
+			window.Increase()
+			err = recv(res.seg, res.d)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		congested := res.err == ErrTimeout
+		if nack, ok := res.err.(*NackError); ok {
+			switch nack.Reason {
+			case NackReasonCongestion:
+				congested = true
+			case NackReasonDuplicate:
+			default:
+				if !pastEnd(res.seg) {
+					return res.err
+				}
+				continue
+			}
+		} else if !congested {
+			return res.err
+		}
+		retries[res.seg]++
+		if retries[res.seg] > f.MaxRetries {
+			return res.err
+		}
+		// decrease at most once per round trip
+		if congested && res.sent.After(lastDecrease) {
+			window.Decrease()
+			lastDecrease = time.Now()
+		}
+		retx = append(retx, res.seg)
+	}
+}
@@ -0,0 +1,152 @@
+package ndn
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/go-ndn/tlv"
+)
+
+// segmentSender serves a segmented object, and drops or nacks
+// the first attempt of some segments.
+//
+// If noFinalBlockID is set, segments past the end are nacked
+// or answered with application nack instead.
+type segmentSender struct {
+	prefix         Name
+	metadata       bool
+	metadataPrefix Name // versioned name in metadata if it is not prefix
+	noFinalBlockID bool
+	segments       [][]byte
+	sync.Mutex
+	attempts map[string]int
+}
+
+func (s *segmentSender) SendInterest(i *Interest) (*Data, error) {
+	s.Lock()
+	s.attempts[i.Name.String()]++
+	attempt := s.attempts[i.Name.String()]
+	s.Unlock()
+
+	l := s.prefix.Len()
+	if i.CanBePrefix {
+		if keyword, ok := i.Name.Keyword(); ok && bytes.Equal(keyword, metadataKeyword) {
+			if !s.metadata {
+				return nil, ErrTimeout
+			}
+			versioned := s.prefix
+			if s.metadataPrefix.Len() != 0 {
+				versioned = s.metadataPrefix
+			}
+			buf := new(bytes.Buffer)
+			err := versioned.WriteTo(tlv.NewWriter(buf))
+			if err != nil {
+				return nil, err
+			}
+			return &Data{
+				Name:    i.Name.AppendVersion(1).AppendSegment(0),
+				Content: buf.Bytes(),
+			}, nil
+		}
+		return s.segment(0), nil
+	}
+	if i.Name.Len() != l+1 {
+		return nil, &NackError{Reason: NackReasonNoRoute}
+	}
+	seg, ok := i.Name.Segment()
+	if !ok {
+		return nil, ErrTimeout
+	}
+	if seg >= uint64(len(s.segments)) {
+		if !s.noFinalBlockID {
+			return nil, ErrTimeout
+		}
+		if seg%2 == 0 {
+			return nil, &NackError{Reason: NackReasonNoRoute}
+		}
+		return &Data{
+			Name: i.Name,
+			MetaInfo: MetaInfo{
+				ContentType: ContentTypeNack,
+			},
+		}, nil
+	}
+	if attempt == 1 {
+		switch seg % 5 {
+		case 1:
+			return nil, ErrTimeout
+		case 3:
+			return nil, &NackError{Reason: NackReasonCongestion}
+		}
+	}
+	return s.segment(seg), nil
+}
+
+func (s *segmentSender) SendData(*Data) error {
+	return nil
+}
+
+func (s *segmentSender) segment(seg uint64) *Data {
+	d := &Data{
+		Name:    s.prefix.AppendSegment(seg),
+		Content: s.segments[seg],
+	}
+	if !s.noFinalBlockID {
+		d.MetaInfo.FinalBlockID = NewFinalBlockID(s.prefix.AppendSegment(uint64(len(s.segments) - 1)))
+	}
+	return d
+}
+
+func TestFetcher(t *testing.T) {
+	var (
+		want     []byte
+		segments [][]byte
+	)
+	for i := 0; i < 100; i++ {
+		seg := bytes.Repeat([]byte{byte(i)}, 10)
+		segments = append(segments, seg)
+		want = append(want, seg...)
+	}
+	for _, test := range []struct {
+		newWindow      func() CongestionWindow
+		metadata       bool
+		metadataPrefix Name
+		name           Name
+		noFinalBlockID bool
+	}{
+		{newWindow: NewAIMD, metadata: true, name: NewName("/A")},
+		{newWindow: NewCUBIC, name: NewName("/A")},
+		{newWindow: NewAIMD, name: NewName("/A").AppendVersion(1)},
+		{newWindow: NewAIMD, name: NewName("/A"), noFinalBlockID: true},
+		// metadata outside of the prefix is ignored
+		{newWindow: NewAIMD, metadata: true, metadataPrefix: NewName("/B").AppendVersion(1), name: NewName("/A")},
+		{newWindow: NewAIMD, metadata: true, metadataPrefix: NewName("/A/B"), name: NewName("/A")},
+	} {
+		// interests past the end might be still pending after fetching
+		s := &segmentSender{
+			prefix:         NewName("/A").AppendVersion(1),
+			metadata:       test.metadata,
+			metadataPrefix: test.metadataPrefix,
+			noFinalBlockID: test.noFinalBlockID,
+			segments:       segments,
+			attempts:       make(map[string]int),
+		}
+		f := &Fetcher{
+			Sender:     s,
+			NewWindow:  test.newWindow,
+			MaxRetries: 1,
+		}
+		r := f.Fetch(context.Background(), test.name)
+		got, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(want, got) {
+			t.Fatalf("expect %v, got %v", want, got)
+		}
+	}
+}
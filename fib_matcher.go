@@ -0,0 +1,114 @@
+package ndn
+
+import (
+	"github.com/go-ndn/lpm"
+)
+
+type fibMatcher struct{ fibNode }
+type fibNode struct {
+	val   *[]NextHopRecord
+	table map[string]*fibNode
+}
+
+func (n *fibNode) Empty() bool {
+	return n.val == nil && len(n.table) == 0
+}
+func fibDeref(val *[]NextHopRecord) ([]NextHopRecord, bool) {
+	if val == nil {
+		var t []NextHopRecord
+		return t, false
+	}
+	return *val, true
+}
+func (n *fibNode) Match(key []lpm.Component) (val []NextHopRecord, found bool) {
+	if len(key) == 0 {
+		return fibDeref(n.val)
+	}
+	if n.table == nil {
+		return fibDeref(n.val)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return fibDeref(n.val)
+	}
+	return child.Match(key[1:])
+}
+func (n *fibNode) Get(key []lpm.Component) (val []NextHopRecord, found bool) {
+	if len(key) == 0 {
+		return fibDeref(n.val)
+	}
+	if n.table == nil {
+		return fibDeref(nil)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return fibDeref(nil)
+	}
+	return child.Get(key[1:])
+}
+func (n *fibNode) Update(key []lpm.Component, val []NextHopRecord) {
+	if len(key) == 0 {
+		n.val = &val
+		return
+	}
+	if n.table == nil {
+		n.table = make(map[string]*fibNode)
+	}
+	if _, ok := n.table[string(key[0])]; !ok {
+		n.table[string(key[0])] = &fibNode{}
+	}
+	n.table[string(key[0])].Update(key[1:], val)
+}
+func (n *fibNode) Delete(key []lpm.Component) {
+	if len(key) == 0 {
+		n.val = nil
+		return
+	}
+	if n.table == nil {
+		return
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return
+	}
+	child.Delete(key[1:])
+	if child.Empty() {
+		delete(n.table, string(key[0]))
+	}
+}
+
+type fibUpdateFunc func([]lpm.Component, []NextHopRecord) (val []NextHopRecord, del bool)
+
+func (n *fibNode) UpdateAll(key []lpm.Component, f fibUpdateFunc) {
+	for i := len(key); i > 0; i-- {
+		k := key[:i]
+		val, _ := n.Get(k)
+		val2, del := f(k, val)
+		if !del {
+			n.Update(k, val2)
+		} else {
+			n.Delete(k)
+		}
+	}
+}
+func (n *fibNode) visit(key []lpm.Component, f func([]lpm.Component)) {
+	for k, v := range n.table {
+		v.visit(append(key, lpm.Component(k)), f)
+	}
+	if n.val != nil {
+		f(key)
+	}
+}
+func (n *fibNode) Visit(f fibUpdateFunc) {
+	n.visit(make([]lpm.Component, 0, 16), func(k []lpm.Component) {
+		val, found := n.Get(k)
+		if found {
+			val2, del := f(k, val)
+			if !del {
+				n.Update(k, val2)
+			} else {
+				n.Delete(k)
+			}
+		}
+	})
+}
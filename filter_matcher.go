@@ -0,0 +1,114 @@
+package ndn
+
+import (
+	"github.com/go-ndn/lpm"
+)
+
+type filterMatcher struct{ filterNode }
+type filterNode struct {
+	val   *Handler
+	table map[string]*filterNode
+}
+
+func (n *filterNode) Empty() bool {
+	return n.val == nil && len(n.table) == 0
+}
+func filterDeref(val *Handler) (Handler, bool) {
+	if val == nil {
+		var t Handler
+		return t, false
+	}
+	return *val, true
+}
+func (n *filterNode) Match(key []lpm.Component) (val Handler, found bool) {
+	if len(key) == 0 {
+		return filterDeref(n.val)
+	}
+	if n.table == nil {
+		return filterDeref(n.val)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return filterDeref(n.val)
+	}
+	return child.Match(key[1:])
+}
+func (n *filterNode) Get(key []lpm.Component) (val Handler, found bool) {
+	if len(key) == 0 {
+		return filterDeref(n.val)
+	}
+	if n.table == nil {
+		return filterDeref(nil)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return filterDeref(nil)
+	}
+	return child.Get(key[1:])
+}
+func (n *filterNode) Update(key []lpm.Component, val Handler) {
+	if len(key) == 0 {
+		n.val = &val
+		return
+	}
+	if n.table == nil {
+		n.table = make(map[string]*filterNode)
+	}
+	if _, ok := n.table[string(key[0])]; !ok {
+		n.table[string(key[0])] = &filterNode{}
+	}
+	n.table[string(key[0])].Update(key[1:], val)
+}
+func (n *filterNode) Delete(key []lpm.Component) {
+	if len(key) == 0 {
+		n.val = nil
+		return
+	}
+	if n.table == nil {
+		return
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return
+	}
+	child.Delete(key[1:])
+	if child.Empty() {
+		delete(n.table, string(key[0]))
+	}
+}
+
+type filterUpdateFunc func([]lpm.Component, Handler) (val Handler, del bool)
+
+func (n *filterNode) UpdateAll(key []lpm.Component, f filterUpdateFunc) {
+	for i := len(key); i > 0; i-- {
+		k := key[:i]
+		val, _ := n.Get(k)
+		val2, del := f(k, val)
+		if !del {
+			n.Update(k, val2)
+		} else {
+			n.Delete(k)
+		}
+	}
+}
+func (n *filterNode) visit(key []lpm.Component, f func([]lpm.Component)) {
+	for k, v := range n.table {
+		v.visit(append(key, lpm.Component(k)), f)
+	}
+	if n.val != nil {
+		f(key)
+	}
+}
+func (n *filterNode) Visit(f filterUpdateFunc) {
+	n.visit(make([]lpm.Component, 0, 16), func(k []lpm.Component) {
+		val, found := n.Get(k)
+		if found {
+			val2, del := f(k, val)
+			if !del {
+				n.Update(k, val2)
+			} else {
+				n.Delete(k)
+			}
+		}
+	})
+}
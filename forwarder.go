@@ -0,0 +1,400 @@
+package ndn
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-ndn/lpm"
+)
+
+// deadNonceLifetime is how long nonces of satisfied interests are remembered
+// for loop detection.
+const deadNonceLifetime = 6 * time.Second
+
+// Forwarder forwards interests and data packets among faces.
+//
+// An incoming interest is answered from the content store if possible.
+// Otherwise, it is aggregated with a pending interest that selects the same
+// data, or forwarded to next hops in the FIB chosen by the forwarding
+// strategy of the longest matching prefix. Returned data packets are sent
+// to all downstream faces of the pending interests that they satisfy,
+// and added to the content store.
+//
+// An interest with a nonce that is pending or recently satisfied is nacked
+// with NackReasonDuplicate, and an interest without any next hop is nacked
+// with NackReasonNoRoute.
+type Forwarder struct {
+	cs         Cache
+	fib        fibMatcher
+	strategies strategyMatcher
+	faces      map[uint64]Face
+	nextFaceID uint64
+	pit        pendingMatcher
+	deadNonces map[string]time.Time // name and nonce -> time satisfied
+	deadQueue  []nonceRecord
+	mu         sync.Mutex
+}
+
+type forwarderEntry struct {
+	*Interest
+	key        []lpm.Component
+	downstream map[uint64]*Interest // face id -> interest
+	nonces     map[string]struct{}
+	cancel     context.CancelFunc
+}
+
+// NewForwarder creates a forwarder with content store c.
+//
+// If c is nil, data packets are not cached.
+// The default forwarding strategy is NewBestRoute.
+func NewForwarder(c Cache) *Forwarder {
+	fw := &Forwarder{
+		cs:         c,
+		faces:      make(map[uint64]Face),
+		deadNonces: make(map[string]time.Time),
+	}
+	fw.strategies.Update(nil, NewBestRoute())
+	return fw
+}
+
+// AddFace attaches f that delivers incoming interests to recv,
+// and returns its face id.
+//
+// The face is removed when recv is closed.
+func (fw *Forwarder) AddFace(f Face, recv <-chan *Interest) uint64 {
+	fw.mu.Lock()
+	fw.nextFaceID++
+	id := fw.nextFaceID
+	fw.faces[id] = f
+	fw.mu.Unlock()
+	go func() {
+		for i := range recv {
+			fw.recvInterest(id, i)
+		}
+		fw.RemoveFace(id)
+	}()
+	return id
+}
+
+// RemoveFace detaches the face and removes its routes.
+//
+// The face is not closed.
+func (fw *Forwarder) RemoveFace(id uint64) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	delete(fw.faces, id)
+	fw.fib.Visit(func(_ []lpm.Component, nextHops []NextHopRecord) ([]NextHopRecord, bool) {
+		nextHops = removeNextHop(nextHops, id)
+		return nextHops, len(nextHops) == 0
+	})
+}
+
+// AddRoute adds or updates the next hop of prefix.
+func (fw *Forwarder) AddRoute(prefix Name, faceID, cost uint64) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	nextHops, _ := fw.fib.Get(prefix.key())
+	nextHops = append(removeNextHop(nextHops, faceID), NextHopRecord{
+		FaceID: faceID,
+		Cost:   cost,
+	})
+	sort.SliceStable(nextHops, func(i, j int) bool {
+		return nextHops[i].Cost < nextHops[j].Cost
+	})
+	fw.fib.Update(prefix.key(), nextHops)
+}
+
+// RemoveRoute removes the next hop of prefix.
+func (fw *Forwarder) RemoveRoute(prefix Name, faceID uint64) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	nextHops, ok := fw.fib.Get(prefix.key())
+	if !ok {
+		return
+	}
+	nextHops = removeNextHop(nextHops, faceID)
+	if len(nextHops) == 0 {
+		fw.fib.Delete(prefix.key())
+	} else {
+		fw.fib.Update(prefix.key(), nextHops)
+	}
+}
+
+// SetStrategy chooses the forwarding strategy for interests under prefix.
+func (fw *Forwarder) SetStrategy(prefix Name, s ForwardingStrategy) {
+	fw.mu.Lock()
+	fw.strategies.Update(prefix.key(), s)
+	fw.mu.Unlock()
+}
+
+// removeNextHop returns a copy of next hops without the face.
+func removeNextHop(nextHops []NextHopRecord, faceID uint64) []NextHopRecord {
+	var removed []NextHopRecord
+	for _, nh := range nextHops {
+		if nh.FaceID != faceID {
+			removed = append(removed, nh)
+		}
+	}
+	return removed
+}
+
+func (fw *Forwarder) face(id uint64) (Face, bool) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	f, ok := fw.faces[id]
+	return f, ok
+}
+
+func (fw *Forwarder) sendNack(id uint64, i *Interest, reason uint64) {
+	if f, ok := fw.face(id); ok {
+		f.SendNack(i, reason)
+	}
+}
+
+func (fw *Forwarder) recvInterest(in uint64, i *Interest) {
+	key := i.Name.key()
+	nonce := i.Name.String() + string(i.Nonce)
+
+	fw.mu.Lock()
+	fw.expireNonces(time.Now())
+	if fw.looped(key, nonce) {
+		fw.mu.Unlock()
+		fw.sendNack(in, i, NackReasonDuplicate)
+		return
+	}
+	if fw.cs != nil {
+		if d := fw.cs.Get(i); d != nil {
+			f, ok := fw.faces[in]
+			fw.mu.Unlock()
+			if ok {
+				f.SendData(d)
+			}
+			return
+		}
+	}
+	entries, _ := fw.pit.Get(key)
+	for _, e := range entries {
+		if sameSelection(e.Interest, i) {
+			e.downstream[in] = i
+			e.nonces[nonce] = struct{}{}
+			fw.mu.Unlock()
+			return
+		}
+	}
+	if i.HopLimit != nil && *i.HopLimit <= 1 {
+		// no more hop is allowed
+		fw.mu.Unlock()
+		return
+	}
+
+	var (
+		nextHops []NextHopRecord
+		strategy ForwardingStrategy
+	)
+	for l := len(key); l >= 0 && (nextHops == nil || strategy == nil); l-- {
+		prefix := key[:l]
+		if nextHops == nil {
+			nextHops, _ = fw.fib.Get(prefix)
+		}
+		if strategy == nil {
+			strategy, _ = fw.strategies.Get(prefix)
+		}
+	}
+	var upstream []Face
+	for _, id := range strategy.Forward(i, in, removeNextHop(nextHops, in)) {
+		if f, ok := fw.faces[id]; ok && id != in {
+			upstream = append(upstream, f)
+		}
+	}
+	if len(upstream) == 0 {
+		fw.mu.Unlock()
+		fw.sendNack(in, i, NackReasonNoRoute)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &forwarderEntry{
+		Interest: i,
+		key:      key,
+		downstream: map[uint64]*Interest{
+			in: i,
+		},
+		nonces: map[string]struct{}{
+			nonce: {},
+		},
+		cancel: cancel,
+	}
+	fw.pit.Update(key, append(entries, e))
+	fw.mu.Unlock()
+
+	go fw.forward(ctx, e, upstream)
+}
+
+// looped checks whether the nonce is pending or recently satisfied.
+//
+// Forwarder mutex must be held.
+func (fw *Forwarder) looped(key []lpm.Component, nonce string) bool {
+	if _, ok := fw.deadNonces[nonce]; ok {
+		return true
+	}
+	entries, _ := fw.pit.Get(key)
+	for _, e := range entries {
+		if _, ok := e.nonces[nonce]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expireNonces forgets nonces that are satisfied long ago.
+//
+// Forwarder mutex must be held.
+func (fw *Forwarder) expireNonces(now time.Time) {
+	var n int
+	for _, rec := range fw.deadQueue {
+		if now.Sub(rec.seen) <= deadNonceLifetime {
+			break
+		}
+		delete(fw.deadNonces, rec.nonce)
+		n++
+	}
+	fw.deadQueue = fw.deadQueue[n:]
+}
+
+// removeEntry removes e from pit.
+// It returns false if e is no longer pending.
+//
+// Forwarder mutex must be held.
+func (fw *Forwarder) removeEntry(e *forwarderEntry) bool {
+	entries, _ := fw.pit.Get(e.key)
+	for j, e2 := range entries {
+		if e2 == e {
+			fw.updateEntries(e.key, append(entries[:j:j], entries[j+1:]...))
+			return true
+		}
+	}
+	return false
+}
+
+// removeMatch removes and returns all pending interests that d satisfies.
+// Like face.recvData, the name of a satisfied interest is a prefix of d.Name.
+//
+// Forwarder mutex must be held.
+func (fw *Forwarder) removeMatch(d *Data) []*forwarderEntry {
+	var matched []*forwarderEntry
+	key := d.Name.key()
+	for l := len(key); l >= 0; l-- {
+		entries, ok := fw.pit.Get(key[:l])
+		if !ok {
+			continue
+		}
+		var remain []*forwarderEntry
+		for _, e := range entries {
+			if e.Match(d) {
+				matched = append(matched, e)
+			} else {
+				remain = append(remain, e)
+			}
+		}
+		fw.updateEntries(key[:l], remain)
+	}
+	return matched
+}
+
+// updateEntries replaces the pending interests of key.
+//
+// Forwarder mutex must be held.
+func (fw *Forwarder) updateEntries(key []lpm.Component, entries []*forwarderEntry) {
+	if len(entries) == 0 {
+		fw.pit.Delete(key)
+	} else {
+		fw.pit.Update(key, entries)
+	}
+}
+
+type forwardResult struct {
+	d   *Data
+	err error
+}
+
+// forward sends the pending interest to upstream faces, and returns
+// the first data packet or the least severe nack to downstream faces.
+//
+// The data packet also satisfies other pending interests that it matches,
+// and their forwarding is canceled.
+func (fw *Forwarder) forward(ctx context.Context, e *forwarderEntry, upstream []Face) {
+	defer e.cancel()
+	out := *e.Interest
+	if out.HopLimit != nil {
+		hopLimit := *out.HopLimit - 1
+		out.HopLimit = &hopLimit
+	}
+	results := make(chan forwardResult, len(upstream))
+	for _, f := range upstream {
+		go func(f Face, i Interest) {
+			d, err := f.SendInterestContext(ctx, &i)
+			results <- forwardResult{d: d, err: err}
+		}(f, out)
+	}
+	var (
+		d    *Data
+		nack *NackError
+	)
+	for range upstream {
+		res := <-results
+		if res.err == nil {
+			d = res.d
+			break
+		}
+		if err, ok := res.err.(*NackError); ok && (nack == nil || err.Reason < nack.Reason) {
+			nack = err
+		}
+	}
+	e.cancel()
+
+	fw.mu.Lock()
+	if !fw.removeEntry(e) {
+		// already satisfied by data of another pending interest
+		fw.mu.Unlock()
+		return
+	}
+	satisfied := []*forwarderEntry{e}
+	if d != nil {
+		satisfied = append(satisfied, fw.removeMatch(d)...)
+		now := time.Now()
+		for _, e := range satisfied {
+			e.cancel()
+			for nonce := range e.nonces {
+				fw.deadNonces[nonce] = now
+				fw.deadQueue = append(fw.deadQueue, nonceRecord{
+					nonce: nonce,
+					seen:  now,
+				})
+			}
+		}
+	}
+	downstream := make(map[Face]*Interest)
+	for _, e := range satisfied {
+		for id, i := range e.downstream {
+			if f, ok := fw.faces[id]; ok {
+				downstream[f] = i
+			}
+		}
+	}
+	fw.mu.Unlock()
+
+	switch {
+	case d != nil:
+		if fw.cs != nil {
+			fw.cs.Add(d)
+		}
+		for f := range downstream {
+			f.SendData(d)
+		}
+	case nack != nil:
+		for f, i := range downstream {
+			f.SendNack(i, nack.Reason)
+		}
+	}
+}
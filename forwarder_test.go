@@ -0,0 +1,203 @@
+package ndn
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// attachFace connects a new application face to the forwarder.
+func attachFace(fw *Forwarder, recv chan<- *Interest) (Face, uint64) {
+	c1, c2 := net.Pipe()
+	fwRecv := make(chan *Interest, 16)
+	id := fw.AddFace(NewFace(c2, fwRecv), fwRecv)
+	return NewFace(c1, recv), id
+}
+
+// countingProducer answers all interests after delay, and counts them.
+type countingProducer struct {
+	Face
+	delay time.Duration
+	sync.Mutex
+	count map[string]int
+}
+
+func newCountingProducer(fw *Forwarder, delay time.Duration) (*countingProducer, uint64) {
+	recv := make(chan *Interest, 16)
+	f, id := attachFace(fw, recv)
+	p := &countingProducer{
+		Face:  f,
+		delay: delay,
+		count: make(map[string]int),
+	}
+	go func() {
+		for i := range recv {
+			p.Lock()
+			p.count[i.Name.String()]++
+			p.Unlock()
+			go func(i *Interest) {
+				time.Sleep(p.delay)
+				p.SendData(&Data{
+					Name: i.Name,
+				})
+			}(i)
+		}
+	}()
+	return p, id
+}
+
+func (p *countingProducer) get(name string) int {
+	p.Lock()
+	defer p.Unlock()
+	return p.count[name]
+}
+
+func TestForwarder(t *testing.T) {
+	fw := NewForwarder(NewCache(16))
+	p1, id1 := newCountingProducer(fw, 100*time.Millisecond)
+	defer p1.Close()
+	p2, id2 := newCountingProducer(fw, 0)
+	defer p2.Close()
+	fw.AddRoute(NewName("/A"), id1, 10)
+	fw.AddRoute(NewName("/A"), id2, 20)
+	fw.SetStrategy(NewName("/A/multicast"), NewMulticast())
+
+	c1, _ := attachFace(fw, nil)
+	defer c1.Close()
+	c2, _ := attachFace(fw, nil)
+	defer c2.Close()
+
+	// aggregation
+	var wg sync.WaitGroup
+	for _, c := range []Face{c1, c2} {
+		wg.Add(1)
+		go func(c Face) {
+			defer wg.Done()
+			_, err := c.SendInterest(&Interest{
+				Name: NewName("/A/1"),
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(c)
+	}
+	wg.Wait()
+	if got := p1.get("/A/1"); got != 1 {
+		t.Fatalf("expect 1 interest at best route, got %d", got)
+	}
+	if got := p2.get("/A/1"); got != 0 {
+		t.Fatalf("expect no interest at other route, got %d", got)
+	}
+
+	// content store
+	_, err := c1.SendInterest(&Interest{
+		Name: NewName("/A/1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p1.get("/A/1"); got != 1 {
+		t.Fatalf("expect data from content store, got %d interests", got)
+	}
+	hopLimit := uint8(1)
+	_, err = c1.SendInterest(&Interest{
+		Name:     NewName("/A/1"),
+		HopLimit: &hopLimit,
+	})
+	if err != nil {
+		t.Fatalf("expect data from content store with hop limit 1, got %v", err)
+	}
+
+	// multicast
+	_, err = c1.SendInterest(&Interest{
+		Name: NewName("/A/multicast"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p1.get("/A/multicast") != 1 || p2.get("/A/multicast") != 1 {
+		t.Fatal("expect interest at all routes")
+	}
+
+	// no route
+	_, err = c1.SendInterest(&Interest{
+		Name: NewName("/B"),
+	})
+	if nack, ok := err.(*NackError); !ok || nack.Reason != NackReasonNoRoute {
+		t.Fatalf("expect nack NoRoute, got %v", err)
+	}
+
+	// loop
+	nonce := []byte{1, 2, 3, 4}
+	go c1.SendInterest(&Interest{
+		Name:  NewName("/A/2"),
+		Nonce: nonce,
+	})
+	time.Sleep(50 * time.Millisecond)
+	_, err = c2.SendInterest(&Interest{
+		Name:  NewName("/A/2"),
+		Nonce: nonce,
+	})
+	if nack, ok := err.(*NackError); !ok || nack.Reason != NackReasonDuplicate {
+		t.Fatalf("expect nack Duplicate, got %v", err)
+	}
+
+	// face removal
+	fw.RemoveFace(id1)
+	_, err = c1.SendInterest(&Interest{
+		Name: NewName("/A/3"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p2.get("/A/3"); got != 1 {
+		t.Fatalf("expect interest at remaining route, got %d", got)
+	}
+}
+
+func TestForwarderCanBePrefix(t *testing.T) {
+	fw := NewForwarder(nil)
+	recv := make(chan *Interest, 16)
+	p, id := attachFace(fw, recv)
+	defer p.Close()
+	fw.AddRoute(NewName("/A"), id, 0)
+	go func() {
+		// only prefix interests are answered
+		for i := range recv {
+			if i.CanBePrefix {
+				p.SendData(&Data{
+					Name: NewName(i.Name.String() + "/1"),
+				})
+			}
+		}
+	}()
+
+	c1, _ := attachFace(fw, nil)
+	defer c1.Close()
+	c2, _ := attachFace(fw, nil)
+	defer c2.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c2.SendInterest(&Interest{
+			Name:     NewName("/A/1"),
+			LifeTime: 1000,
+		})
+		errc <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	d, err := c1.SendInterest(&Interest{
+		Name:        NewName("/A"),
+		CanBePrefix: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/A/1"; d.Name.String() != want {
+		t.Fatalf("expect %s, got %s", want, d.Name)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("expect pending interest to be satisfied, got %v", err)
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"hash"
-
-	"github.com/go-ndn/tlv"
 )
 
 // HMACKey implements Key.
@@ -36,7 +34,7 @@ func (key *HMACKey) SignatureType() uint64 {
 
 // Sign creates signature.
 func (key *HMACKey) Sign(v interface{}) ([]byte, error) {
-	return tlv.Hash(func() hash.Hash {
+	return hashSigned(func() hash.Hash {
 		return hmac.New(sha256.New, key.PrivateKey)
 	}, v)
 }
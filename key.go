@@ -2,11 +2,15 @@ package ndn
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
+	"hash"
 	"io"
 	"io/ioutil"
 	"time"
@@ -22,10 +26,11 @@ var (
 )
 
 const (
-	pemHeaderName = "NAME"
-	pemTypeRSA    = "RSA PRIVATE KEY"
-	pemTypeECDSA  = "ECDSA PRIVATE KEY"
-	pemTypeHMAC   = "HMAC PRIVATE KEY"
+	pemHeaderName  = "NAME"
+	pemTypeRSA     = "RSA PRIVATE KEY"
+	pemTypeECDSA   = "ECDSA PRIVATE KEY"
+	pemTypeHMAC    = "HMAC PRIVATE KEY"
+	pemTypeEd25519 = "ED25519 PRIVATE KEY"
 )
 
 // Key signs and verifies data packets.
@@ -40,6 +45,54 @@ type Key interface {
 	Verify(interface{}, []byte) error
 }
 
+// GenerateKey creates a private key named by name for the signature type.
+//
+// RSA keys are 2048 bits, ECDSA keys use P-256, and HMAC keys are 32 bytes.
+func GenerateKey(name Name, signatureType uint64) (Key, error) {
+	switch signatureType {
+	case SignatureTypeSHA256WithRSA:
+		pri, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		return &RSAKey{
+			Name:       name,
+			PrivateKey: pri,
+		}, nil
+	case SignatureTypeSHA256WithECDSA:
+		pri, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &ECDSAKey{
+			Name:       name,
+			PrivateKey: pri,
+		}, nil
+	case SignatureTypeSHA256WithHMAC:
+		pri := make([]byte, 32)
+		_, err := rand.Read(pri)
+		if err != nil {
+			return nil, err
+		}
+		return &HMACKey{
+			Name:       name,
+			PrivateKey: pri,
+		}, nil
+	case SignatureTypeEd25519:
+		pub, pri, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &Ed25519Key{
+			Name:       name,
+			PrivateKey: pri,
+			PublicKey:  pub,
+		}, nil
+	default:
+		return nil, ErrNotSupported
+	}
+}
+
 // EncodePrivateKey encodes the private key in PEM encoding.
 //
 // See DecodePrivateKey.
@@ -52,6 +105,8 @@ func EncodePrivateKey(key Key, w io.Writer) error {
 		keyType = pemTypeECDSA
 	case SignatureTypeSHA256WithHMAC:
 		keyType = pemTypeHMAC
+	case SignatureTypeEd25519:
+		keyType = pemTypeEd25519
 	default:
 		return ErrNotSupported
 	}
@@ -108,29 +163,40 @@ func DecodePrivateKey(r io.Reader) (key Key, err error) {
 			Name:       name,
 			PrivateKey: block.Bytes,
 		}
+	case pemTypeEd25519:
+		var pri interface{}
+		pri, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return
+		}
+		edPri, ok := pri.(ed25519.PrivateKey)
+		if !ok {
+			err = ErrNotSupported
+			return
+		}
+		key = &Ed25519Key{
+			Name:       name,
+			PrivateKey: edPri,
+			PublicKey:  edPri.Public().(ed25519.PublicKey),
+		}
 	default:
 		err = ErrNotSupported
 	}
 	return
 }
 
-// CertificateToData creates a data packet from a self-signed public key.
+// CertificateToData creates a self-signed certificate of key.
 //
-// See CertificateFromData.
-func CertificateToData(key Key) (d *Data, err error) {
-	d = &Data{
-		Name: key.Locator(),
-		MetaInfo: MetaInfo{
-			ContentType:     2,       // key
-			FreshnessPeriod: 3600000, // 1 hour
-		},
-	}
-	d.Content, err = key.Public()
+// The locator of key must be a key name /<identity>/KEY/<key-id>.
+//
+// See CertificateFromData and NewCertificate.
+func CertificateToData(key Key) (*Data, error) {
+	now := time.Now()
+	cert, err := NewCertificate(key, key, nil, now, now.Add(selfSignedValidity))
 	if err != nil {
-		return
+		return nil, err
 	}
-	err = SignData(key, d)
-	return
+	return cert.Data, nil
 }
 
 // EncodeCertificate invokes CertificateToData and encodes
@@ -150,33 +216,22 @@ func EncodeCertificate(key Key, w io.Writer) error {
 	return enc.Close()
 }
 
-// CertificateFromData creates a public key from a data packet.
+// CertificateFromData creates a public key from a certificate.
 //
-// See CertificateToData.
-func CertificateFromData(d *Data) (key Key, err error) {
-	pub, err := x509.ParsePKIXPublicKey(d.Content)
-	if err != nil {
-		return
+// The locator of the public key is the key name.
+// A data packet that is not named as a certificate is decoded in
+// certificate format v1, where the locator is the data name.
+//
+// See CertificateToData and ParseCertificate.
+func CertificateFromData(d *Data) (Key, error) {
+	if !isCertificateName(d.Name) {
+		return publicKey(d.Name, d.Content)
 	}
-	switch pub := pub.(type) {
-	case *rsa.PublicKey:
-		key = &RSAKey{
-			Name: d.Name,
-			PrivateKey: &rsa.PrivateKey{
-				PublicKey: *pub,
-			},
-		}
-	case *ecdsa.PublicKey:
-		key = &ECDSAKey{
-			Name: d.Name,
-			PrivateKey: &ecdsa.PrivateKey{
-				PublicKey: *pub,
-			},
-		}
-	default:
-		err = ErrNotSupported
+	cert, err := ParseCertificate(d)
+	if err != nil {
+		return nil, err
 	}
-	return
+	return cert.Key, nil
 }
 
 // DecodeCertificate decodes a data packet in base64 encoding,
@@ -219,3 +274,65 @@ func VerifyData(key Key, d *Data) error {
 	}
 	return key.Verify(d, d.SignatureValue)
 }
+
+// SignInterest signs an interest with the given key in v0.3 format.
+//
+// SignatureNonce and SignatureTime are populated for replay protection,
+// and SignatureSeqNum of the existing InterestSignatureInfo is kept.
+// ParametersSha256DigestComponent is updated after signing.
+//
+// See https://named-data.net/doc/NDN-packet-spec/current/signed-interest.html.
+func SignInterest(key Key, i *Interest) (err error) {
+	info := &InterestSignatureInfo{
+		SignatureType: key.SignatureType(),
+		KeyLocator: KeyLocator{
+			Name: key.Locator(),
+		},
+		SignatureNonce: make([]byte, 8),
+		SignatureTime:  uint64(time.Now().UnixNano() / 1000000),
+	}
+	_, err = rand.Read(info.SignatureNonce)
+	if err != nil {
+		return
+	}
+	if i.SignatureInfo != nil {
+		info.SignatureSeqNum = i.SignatureInfo.SignatureSeqNum
+	}
+	i.SignatureInfo = info
+	i.SignatureValue, err = key.Sign(signedInterest{i})
+	if err != nil {
+		return
+	}
+	return i.updateParametersDigest()
+}
+
+// VerifyInterest verifies a signed interest with the given key.
+//
+// Replay is not detected. See InterestVerifier.
+func VerifyInterest(key Key, i *Interest) error {
+	if i.SignatureInfo == nil || i.SignatureInfo.SignatureType != key.SignatureType() {
+		return ErrInvalidSignature
+	}
+	return key.Verify(signedInterest{i}, i.SignatureValue)
+}
+
+// signedPortion is implemented by packets whose signed portion
+// is not the tlv encoding of their fields.
+type signedPortion interface {
+	signedPortion() ([]byte, error)
+}
+
+// hashSigned computes the digest of the signed portion of v.
+func hashSigned(f func() hash.Hash, v interface{}) ([]byte, error) {
+	p, ok := v.(signedPortion)
+	if !ok {
+		return tlv.Hash(f, v)
+	}
+	b, err := p.signedPortion()
+	if err != nil {
+		return nil, err
+	}
+	h := f()
+	h.Write(b)
+	return h.Sum(nil), nil
+}
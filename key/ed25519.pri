@@ -0,0 +1,5 @@
+-----BEGIN ED25519 PRIVATE KEY-----
+NAME: /ndn/guest/alice/1434508996774/KEY/%00%01
+
+MC4CAQAwBQYDK2VwBCIEIJsjr9+aar7JuMZPi6H3R672s0aer8NDgNa7OVnxvTlo
+-----END ED25519 PRIVATE KEY-----
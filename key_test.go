@@ -2,16 +2,20 @@ package ndn
 
 import (
 	"bytes"
+	"encoding/base64"
 	"os"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/go-ndn/tlv"
 )
 
 var (
-	rsaKey   = readKey("key/default.pri")
-	ecdsaKey = readKey("key/ecdsa.pri")
-	hmacKey  = readKey("key/hmac.pri")
+	rsaKey     = readKey("key/default.pri")
+	ecdsaKey   = readKey("key/ecdsa.pri")
+	hmacKey    = readKey("key/hmac.pri")
+	ed25519Key = readKey("key/ed25519.pri")
 )
 
 func readKey(file string) Key {
@@ -28,7 +32,7 @@ func readKey(file string) Key {
 }
 
 func TestPrivateKey(t *testing.T) {
-	for _, key1 := range []Key{rsaKey, ecdsaKey, hmacKey} {
+	for _, key1 := range []Key{rsaKey, ecdsaKey, hmacKey, ed25519Key} {
 		buf := new(bytes.Buffer)
 		err := EncodePrivateKey(key1, buf)
 		if err != nil {
@@ -47,17 +51,66 @@ func TestPrivateKey(t *testing.T) {
 }
 
 func TestCertificate(t *testing.T) {
-	for _, key := range []Key{rsaKey, ecdsaKey} {
+	for _, key := range []Key{rsaKey, ecdsaKey, ed25519Key} {
 		buf := new(bytes.Buffer)
 		err := EncodeCertificate(key, buf)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		_, err = DecodeCertificate(buf)
+		pub, err := DecodeCertificate(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := new(Data)
+		err = SignData(key, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = VerifyData(pub, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// certificate format v1
+		v1 := &Data{
+			Name: key.Locator(),
+			MetaInfo: MetaInfo{
+				ContentType: ContentTypeKey,
+			},
+		}
+		v1.Content, err = key.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err = CertificateFromData(v1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locator := pub.Locator(); locator.Compare(v1.Name) != 0 {
+			t.Fatalf("expect locator %v, got %v", v1.Name, locator)
+		}
+	}
+
+	for _, file := range []string{"key/default.ndncert", "key/ecdsa.ndncert", "key/ed25519.ndncert"} {
+		f, err := os.Open(file)
 		if err != nil {
 			t.Fatal(err)
 		}
+		d := new(Data)
+		err = d.ReadFrom(tlv.NewReader(base64.NewDecoder(base64.StdEncoding, f)))
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := ParseCertificate(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cert.Valid(time.Now()) {
+			t.Fatalf("%s: unexpected validity %v-%v", file, cert.NotBefore, cert.NotAfter)
+		}
 	}
 }
 
@@ -71,7 +124,7 @@ func TestSignVerify(t *testing.T) {
 			},
 		},
 	}
-	for _, key := range []Key{rsaKey, ecdsaKey, hmacKey} {
+	for _, key := range []Key{rsaKey, ecdsaKey, hmacKey, ed25519Key} {
 		err := SignData(key, d)
 		if err != nil {
 			t.Fatal(err)
@@ -0,0 +1,541 @@
+package ndn
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+// Errors introduced by KeyChain.
+var (
+	ErrNotFound = errors.New("not found")
+)
+
+// KeyChain manages identities, keys and certificates in a local directory.
+//
+// Private keys are stored in PEM encoding under tpm/, certificates are stored
+// in base64 encoding under pib/, and default choices are stored in the file
+// "default". All of them are human-readable, and compatible with
+// DecodePrivateKey and DecodeCertificate.
+//
+// Each identity has a default key, and each key has a default certificate.
+type KeyChain struct {
+	dir             string
+	keys            map[string]Key          // key name -> private key
+	certs           map[string]*Certificate // certificate name -> certificate
+	defaultIdentity Name
+	defaultKeys     map[string]Name // identity -> key name
+	defaultCerts    map[string]Name // key name -> certificate name
+	mu              sync.Mutex
+}
+
+const (
+	keyChainTPM     = "tpm"
+	keyChainPIB     = "pib"
+	keyChainDefault = "default"
+
+	defaultIdentityPrefix    = "identity "
+	defaultKeyPrefix         = "key "
+	defaultCertificatePrefix = "certificate "
+)
+
+// NewKeyChain opens the key chain in dir, or creates it if it does not exist.
+func NewKeyChain(dir string) (*KeyChain, error) {
+	kc := &KeyChain{
+		dir:          dir,
+		keys:         make(map[string]Key),
+		certs:        make(map[string]*Certificate),
+		defaultKeys:  make(map[string]Name),
+		defaultCerts: make(map[string]Name),
+	}
+	for _, sub := range []string{keyChainTPM, keyChainPIB} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0700)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := kc.load()
+	if err != nil {
+		return nil, err
+	}
+	return kc, nil
+}
+
+// fileName creates a file name that is safe for any name.
+func fileName(n Name, ext string) string {
+	return fmt.Sprintf("%x%s", sha256.Sum256([]byte(n.String())), ext)
+}
+
+// writeFile replaces the file at path atomically.
+func writeFile(path string, f func(io.Writer) error) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = f(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (kc *KeyChain) load() error {
+	paths, err := filepath.Glob(filepath.Join(kc.dir, keyChainTPM, "*.pri"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		key, err := readKeyFile(path)
+		if err != nil {
+			return err
+		}
+		kc.keys[key.Locator().String()] = key
+	}
+
+	paths, err = filepath.Glob(filepath.Join(kc.dir, keyChainPIB, "*.ndncert"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		cert, err := readCertificateFile(path)
+		if err != nil {
+			return err
+		}
+		kc.certs[cert.Name.String()] = cert
+	}
+
+	file, err := os.Open(filepath.Join(kc.dir, keyChainDefault))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, defaultIdentityPrefix):
+			kc.defaultIdentity = NewName(strings.TrimPrefix(line, defaultIdentityPrefix))
+		case strings.HasPrefix(line, defaultKeyPrefix):
+			keyName := NewName(strings.TrimPrefix(line, defaultKeyPrefix))
+			kc.defaultKeys[identityOf(keyName).String()] = keyName
+		case strings.HasPrefix(line, defaultCertificatePrefix):
+			certName := NewName(strings.TrimPrefix(line, defaultCertificatePrefix))
+			kc.defaultCerts[certName.Prefix(certName.Len()-2).String()] = certName
+		}
+	}
+	return scanner.Err()
+}
+
+func readKeyFile(path string) (Key, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return DecodePrivateKey(file)
+}
+
+func readCertificateFile(path string) (*Certificate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	d := new(Data)
+	err = d.ReadFrom(tlv.NewReader(base64.NewDecoder(base64.StdEncoding, file)))
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificate(d)
+}
+
+// identityOf returns the identity of key name /<identity>/KEY/<key-id>.
+func identityOf(keyName Name) Name {
+	return keyName.Prefix(keyName.Len() - 2)
+}
+
+// saveDefault writes default choices.
+func (kc *KeyChain) saveDefault() error {
+	return writeFile(filepath.Join(kc.dir, keyChainDefault), func(w io.Writer) error {
+		if kc.defaultIdentity.Len() > 0 {
+			_, err := fmt.Fprintf(w, "%s%s\n", defaultIdentityPrefix, kc.defaultIdentity)
+			if err != nil {
+				return err
+			}
+		}
+		for _, keyName := range sortedNames(kc.defaultKeys) {
+			_, err := fmt.Fprintf(w, "%s%s\n", defaultKeyPrefix, keyName)
+			if err != nil {
+				return err
+			}
+		}
+		for _, certName := range sortedNames(kc.defaultCerts) {
+			_, err := fmt.Fprintf(w, "%s%s\n", defaultCertificatePrefix, certName)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func sortedNames(m map[string]Name) []Name {
+	names := make([]Name, 0, len(m))
+	for _, n := range m {
+		names = append(names, n)
+	}
+	sortNames(names)
+	return names
+}
+
+func sortNames(names []Name) {
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].Compare(names[j]) < 0
+	})
+}
+
+// CreateIdentity generates a new key of the signature type for identity
+// with a self-signed certificate.
+//
+// The identity becomes the default identity if there is none.
+func (kc *KeyChain) CreateIdentity(identity Name, signatureType uint64) (Key, error) {
+	key, err := kc.GenerateKey(identity, signatureType)
+	if err != nil {
+		return nil, err
+	}
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.defaultIdentity.Len() == 0 {
+		kc.defaultIdentity = identity
+		err = kc.saveDefault()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// GenerateKey generates a new key of the signature type for identity
+// with a self-signed certificate.
+//
+// The key becomes the default key of identity if there is none.
+// Symmetric keys have no certificate.
+func (kc *KeyChain) GenerateKey(identity Name, signatureType uint64) (Key, error) {
+	keyName, err := NewKeyName(identity)
+	if err != nil {
+		return nil, err
+	}
+	key, err := GenerateKey(keyName, signatureType)
+	if err != nil {
+		return nil, err
+	}
+	err = kc.AddKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if signatureType == SignatureTypeSHA256WithHMAC {
+		return key, nil
+	}
+	now := time.Now()
+	cert, err := NewCertificate(key, key, nil, now, now.Add(selfSignedValidity))
+	if err != nil {
+		return nil, err
+	}
+	err = kc.AddCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AddKey imports a private key named /<identity>/KEY/<key-id>.
+//
+// The key becomes the default key of its identity if there is none.
+func (kc *KeyChain) AddKey(key Key) error {
+	keyName := key.Locator()
+	if !isKeyName(keyName) {
+		return ErrNotSupported
+	}
+	// the file must not be deleted or overwritten before the key is added
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	err := writeFile(filepath.Join(kc.dir, keyChainTPM, fileName(keyName, ".pri")), func(w io.Writer) error {
+		return EncodePrivateKey(key, w)
+	})
+	if err != nil {
+		return err
+	}
+	kc.keys[keyName.String()] = key
+	identity := identityOf(keyName).String()
+	if _, ok := kc.defaultKeys[identity]; !ok {
+		kc.defaultKeys[identity] = keyName
+		return kc.saveDefault()
+	}
+	return nil
+}
+
+// AddCertificate imports a certificate of a key in the key chain.
+//
+// The certificate becomes the default certificate of its key if there is none.
+func (kc *KeyChain) AddCertificate(cert *Certificate) error {
+	keyName := cert.KeyName()
+	// the key must not be deleted before the certificate is written
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if _, ok := kc.keys[keyName.String()]; !ok {
+		return ErrNotFound
+	}
+	err := writeFile(filepath.Join(kc.dir, keyChainPIB, fileName(cert.Name, ".ndncert")), func(w io.Writer) error {
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		err := cert.Data.WriteTo(tlv.NewWriter(enc))
+		if err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	kc.certs[cert.Name.String()] = cert
+	if _, ok := kc.defaultCerts[keyName.String()]; !ok {
+		kc.defaultCerts[keyName.String()] = cert.Name
+		return kc.saveDefault()
+	}
+	return nil
+}
+
+// DeleteIdentity deletes all keys and certificates of identity.
+func (kc *KeyChain) DeleteIdentity(identity Name) error {
+	for _, keyName := range kc.Keys(identity) {
+		err := kc.DeleteKey(keyName)
+		if err != nil {
+			return err
+		}
+	}
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.defaultIdentity.Compare(identity) == 0 {
+		kc.defaultIdentity = Name{}
+		return kc.saveDefault()
+	}
+	return nil
+}
+
+// DeleteKey deletes the key and its certificates.
+func (kc *KeyChain) DeleteKey(keyName Name) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if _, ok := kc.keys[keyName.String()]; !ok {
+		return ErrNotFound
+	}
+	for _, certName := range kc.certificates(keyName) {
+		err := os.Remove(filepath.Join(kc.dir, keyChainPIB, fileName(certName, ".ndncert")))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(kc.certs, certName.String())
+	}
+	err := os.Remove(filepath.Join(kc.dir, keyChainTPM, fileName(keyName, ".pri")))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(kc.keys, keyName.String())
+	delete(kc.defaultCerts, keyName.String())
+
+	identity := identityOf(keyName).String()
+	if defaultKey, ok := kc.defaultKeys[identity]; ok && defaultKey.Compare(keyName) == 0 {
+		delete(kc.defaultKeys, identity)
+	}
+	return kc.saveDefault()
+}
+
+// Identities returns all identities that have keys.
+func (kc *KeyChain) Identities() []Name {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	m := make(map[string]Name)
+	for _, key := range kc.keys {
+		identity := identityOf(key.Locator())
+		m[identity.String()] = identity
+	}
+	return sortedNames(m)
+}
+
+// Keys returns names of all keys of identity.
+func (kc *KeyChain) Keys(identity Name) []Name {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	var names []Name
+	for _, key := range kc.keys {
+		keyName := key.Locator()
+		if id := identityOf(keyName); id.Compare(identity) == 0 {
+			names = append(names, keyName)
+		}
+	}
+	sortNames(names)
+	return names
+}
+
+// Certificates returns names of all certificates of the key.
+func (kc *KeyChain) Certificates(keyName Name) []Name {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.certificates(keyName)
+}
+
+func (kc *KeyChain) certificates(keyName Name) []Name {
+	var names []Name
+	for _, cert := range kc.certs {
+		if certKeyName := cert.KeyName(); certKeyName.Compare(keyName) == 0 {
+			names = append(names, cert.Name)
+		}
+	}
+	sortNames(names)
+	return names
+}
+
+// Key returns the private key.
+func (kc *KeyChain) Key(keyName Name) (Key, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	key, ok := kc.keys[keyName.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+// Certificate returns the certificate.
+func (kc *KeyChain) Certificate(certName Name) (*Certificate, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	cert, ok := kc.certs[certName.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cert, nil
+}
+
+// DefaultIdentity returns the default identity.
+func (kc *KeyChain) DefaultIdentity() (Name, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.defaultIdentity.Len() == 0 {
+		return Name{}, ErrNotFound
+	}
+	return kc.defaultIdentity, nil
+}
+
+// DefaultKey returns the default key of identity.
+//
+// If identity is empty, the default identity is used.
+func (kc *KeyChain) DefaultKey(identity Name) (Key, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if identity.Len() == 0 {
+		identity = kc.defaultIdentity
+	}
+	keyName, ok := kc.defaultKeys[identity.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key, ok := kc.keys[keyName.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+// DefaultCertificate returns the default certificate of the key.
+func (kc *KeyChain) DefaultCertificate(keyName Name) (*Certificate, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	certName, ok := kc.defaultCerts[keyName.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cert, ok := kc.certs[certName.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cert, nil
+}
+
+// SetDefaultIdentity changes the default identity.
+func (kc *KeyChain) SetDefaultIdentity(identity Name) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if _, ok := kc.defaultKeys[identity.String()]; !ok {
+		return ErrNotFound
+	}
+	kc.defaultIdentity = identity
+	return kc.saveDefault()
+}
+
+// SetDefaultKey changes the default key of its identity.
+func (kc *KeyChain) SetDefaultKey(keyName Name) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if _, ok := kc.keys[keyName.String()]; !ok {
+		return ErrNotFound
+	}
+	kc.defaultKeys[identityOf(keyName).String()] = keyName
+	return kc.saveDefault()
+}
+
+// SetDefaultCertificate changes the default certificate of its key.
+func (kc *KeyChain) SetDefaultCertificate(certName Name) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	cert, ok := kc.certs[certName.String()]
+	if !ok {
+		return ErrNotFound
+	}
+	kc.defaultCerts[cert.KeyName().String()] = certName
+	return kc.saveDefault()
+}
+
+// SignData signs d with the default key of identity.
+//
+// If identity is empty, the default identity is used.
+func (kc *KeyChain) SignData(identity Name, d *Data) error {
+	key, err := kc.DefaultKey(identity)
+	if err != nil {
+		return err
+	}
+	return SignData(key, d)
+}
+
+// SignInterest signs i with the default key of identity.
+//
+// If identity is empty, the default identity is used.
+func (kc *KeyChain) SignInterest(identity Name, i *Interest) error {
+	key, err := kc.DefaultKey(identity)
+	if err != nil {
+		return err
+	}
+	return SignInterest(key, i)
+}
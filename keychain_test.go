@@ -0,0 +1,167 @@
+package ndn
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestKeyChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ndn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kc, err := NewKeyChain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := NewName("/test/alice")
+	bob := NewName("/test/bob")
+	aliceKey, err := kc.CreateIdentity(alice, SignatureTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = kc.CreateIdentity(bob, SignatureTypeSHA256WithECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceKey2, err := kc.GenerateKey(alice, SignatureTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = kc.SetDefaultKey(aliceKey2.Locator())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reopen
+	kc, err = NewKeyChain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kc.Identities(); !reflect.DeepEqual(got, []Name{bob, alice}) {
+		t.Fatalf("expect identities %v, got %v", []Name{bob, alice}, got)
+	}
+	if got := kc.Keys(alice); len(got) != 2 {
+		t.Fatalf("expect 2 keys, got %v", got)
+	}
+	identity, err := kc.DefaultIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.String() != alice.String() {
+		t.Fatalf("expect default identity %v, got %v", alice, identity)
+	}
+	key, err := kc.DefaultKey(Name{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(key, aliceKey2) {
+		t.Fatalf("expect default key %v, got %v", aliceKey2.Locator(), key.Locator())
+	}
+	cert, err := kc.DefaultCertificate(aliceKey.Locator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.SelfSigned() {
+		t.Fatal("expect self-signed certificate")
+	}
+
+	d := &Data{
+		Name: NewName("/test/alice/photo"),
+	}
+	err = kc.SignData(alice, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyData(aliceKey2, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := &Interest{
+		Name: NewName("/test/bob/command"),
+	}
+	err = kc.SignInterest(bob, i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobKey, err := kc.DefaultKey(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyInterest(bobKey, i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = kc.DeleteKey(aliceKey2.Locator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = kc.DefaultKey(alice)
+	if err != ErrNotFound {
+		t.Fatalf("expect %v, got %v", ErrNotFound, err)
+	}
+	err = kc.DeleteIdentity(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kc, err = NewKeyChain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kc.Identities(); !reflect.DeepEqual(got, []Name{alice}) {
+		t.Fatalf("expect identities %v, got %v", []Name{alice}, got)
+	}
+	if got := kc.Certificates(aliceKey.Locator()); len(got) != 1 {
+		t.Fatalf("expect 1 certificate, got %v", got)
+	}
+}
+
+func TestKeyChainAddDeleteKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ndn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kc, err := NewKeyChain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyName, err := NewKeyName(NewName("/test/carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := GenerateKey(keyName, SignatureTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			kc.AddKey(key)
+		}()
+		go func() {
+			defer wg.Done()
+			kc.DeleteKey(keyName)
+		}()
+		wg.Wait()
+
+		// the key in memory is the same as on disk
+		_, want := kc.Key(keyName)
+		kc2, err := NewKeyChain(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, got := kc2.Key(keyName); got != want {
+			t.Fatalf("expect %v, got %v", want, got)
+		}
+	}
+}
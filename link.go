@@ -0,0 +1,170 @@
+package ndn
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Link describes an in-memory link between two faces.
+//
+// The zero value is a lossless link without delay or bandwidth limit.
+type Link struct {
+	// Delay is the one-way propagation delay.
+	Delay time.Duration
+	// Loss is the probability that a packet is dropped.
+	Loss float64
+	// Bandwidth is the number of bytes sent per second in each direction.
+	// If it is 0, bandwidth is unlimited.
+	Bandwidth int
+}
+
+// linkQueueSize is the number of packets that can be in flight
+// in each direction before writes block.
+const linkQueueSize = 1024
+
+// NewPipe creates a pair of connected net.Conn over the link.
+//
+// Unlike net.Pipe, writes are buffered, so that both ends can write at the
+// same time without a reader. Each write is sent as one packet, which is
+// dropped or delayed as a whole.
+// Deadlines are not supported.
+func NewPipe(l Link) (net.Conn, net.Conn) {
+	done := make(chan struct{})
+	closeOnce := new(sync.Once)
+	c1 := &linkConn{
+		link:      l,
+		local:     linkAddr("link1"),
+		remote:    linkAddr("link2"),
+		done:      done,
+		closeOnce: closeOnce,
+	}
+	c2 := &linkConn{
+		link:      l,
+		local:     linkAddr("link2"),
+		remote:    linkAddr("link1"),
+		done:      done,
+		closeOnce: closeOnce,
+	}
+	c1.startLink(c2)
+	c2.startLink(c1)
+	return c1, c2
+}
+
+// NewFacePair creates two faces connected by the link.
+//
+// recv1 and recv2 are the incoming interest queues of each face.
+// See NewFace.
+func NewFacePair(l Link, recv1, recv2 chan<- *Interest) (Face, Face) {
+	c1, c2 := NewPipe(l)
+	return NewFace(c1, recv1), NewFace(c2, recv2)
+}
+
+type linkAddr string
+
+func (addr linkAddr) Network() string { return "link" }
+func (addr linkAddr) String() string  { return string(addr) }
+
+type linkPacket struct {
+	b       []byte
+	arrival time.Time
+}
+
+type linkConn struct {
+	link          Link
+	local, remote linkAddr
+
+	r *io.PipeReader // incoming packets
+	w *io.PipeWriter // outgoing packets that arrive at the remote end
+
+	out  chan linkPacket
+	busy time.Time // when the last outgoing packet is fully transmitted
+	mu   sync.Mutex
+
+	done      chan struct{} // closed when either end is closed
+	closeOnce *sync.Once
+}
+
+// startLink connects the outgoing side of c to the incoming side of remote.
+func (c *linkConn) startLink(remote *linkConn) {
+	remote.r, c.w = io.Pipe()
+	c.out = make(chan linkPacket, linkQueueSize)
+	go func() {
+		for {
+			select {
+			case p := <-c.out:
+				timer := time.NewTimer(time.Until(p.arrival))
+				select {
+				case <-timer.C:
+				case <-c.done:
+					timer.Stop()
+					return
+				}
+				_, err := c.w.Write(p.b)
+				if err != nil {
+					return
+				}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+func (c *linkConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *linkConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if c.busy.Before(now) {
+		c.busy = now
+	}
+	if c.link.Bandwidth > 0 {
+		c.busy = c.busy.Add(time.Duration(len(b)) * time.Second / time.Duration(c.link.Bandwidth))
+	}
+	arrival := c.busy.Add(c.link.Delay)
+	c.mu.Unlock()
+
+	if c.link.Loss > 0 && rand.Float64() < c.link.Loss {
+		return len(b), nil
+	}
+	p := linkPacket{
+		b:       append([]byte(nil), b...),
+		arrival: arrival,
+	}
+	select {
+	case c.out <- p:
+		return len(b), nil
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// Close closes both ends of the link.
+//
+// Packets in flight are dropped.
+func (c *linkConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	c.r.Close()
+	c.w.Close()
+	return nil
+}
+
+func (c *linkConn) LocalAddr() net.Addr  { return c.local }
+func (c *linkConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *linkConn) SetDeadline(time.Time) error      { return ErrNotSupported }
+func (c *linkConn) SetReadDeadline(time.Time) error  { return ErrNotSupported }
+func (c *linkConn) SetWriteDeadline(time.Time) error { return ErrNotSupported }
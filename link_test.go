@@ -0,0 +1,92 @@
+package ndn
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func newLinkedProducer(l Link, content []byte) (consumer, producer Face) {
+	recv := make(chan *Interest)
+	consumer, producer = NewFacePair(l, nil, recv)
+	go func() {
+		for i := range recv {
+			producer.SendData(&Data{
+				Name:    i.Name,
+				Content: content,
+			})
+		}
+	}()
+	return
+}
+
+func TestLink(t *testing.T) {
+	for _, test := range []struct {
+		Link
+		content []byte
+		minRTT  time.Duration
+		want    error
+	}{
+		{
+			content: []byte{1, 2, 3},
+		},
+		{
+			Link: Link{
+				Delay: 50 * time.Millisecond,
+			},
+			minRTT: 100 * time.Millisecond,
+		},
+		{
+			Link: Link{
+				Bandwidth: 100 * 1024,
+			},
+			content: bytes.Repeat([]byte{1}, 10*1024),
+			minRTT:  100 * time.Millisecond,
+		},
+		{
+			Link: Link{
+				Loss: 1,
+			},
+			want: ErrTimeout,
+		},
+	} {
+		consumer, producer := newLinkedProducer(test.Link, test.content)
+		start := time.Now()
+		d, err := consumer.SendInterest(&Interest{
+			Name:     NewName("/A"),
+			LifeTime: 500,
+		})
+		rtt := time.Since(start)
+		consumer.Close()
+		producer.Close()
+		if err != test.want {
+			t.Fatalf("expect %v, got %v", test.want, err)
+		}
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(d.Content, test.content) {
+			t.Fatalf("expect content %v, got %v", test.content, d.Content)
+		}
+		if rtt < test.minRTT {
+			t.Fatalf("expect rtt at least %v, got %v", test.minRTT, rtt)
+		}
+	}
+}
+
+func TestLinkClose(t *testing.T) {
+	c1, c2 := NewPipe(Link{})
+	_, err := c1.Write([]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1.Close()
+	_, err = c1.Write([]byte{1})
+	if err == nil {
+		t.Fatal("expect write error after close")
+	}
+	_, err = c2.Read(make([]byte, 1))
+	if err == nil {
+		t.Fatal("expect read error after remote close")
+	}
+}
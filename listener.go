@@ -0,0 +1,132 @@
+package ndn
+
+import (
+	"net"
+	"sort"
+	"sync"
+)
+
+// AcceptedFace is a face accepted by FaceListener.
+type AcceptedFace struct {
+	Face
+	// ID is unique among faces of the listener.
+	ID uint64
+	// Recv is the incoming interest queue, which is closed when the face is
+	// closed. It must be handled before it is full, unless the face is closed.
+	Recv <-chan *Interest
+}
+
+// closed checks whether the face is closed by either side.
+func (f *AcceptedFace) closed() bool {
+	select {
+	case <-f.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// FaceListener accepts connections, and tracks them as faces.
+//
+// A face is removed when it is closed by either side.
+type FaceListener struct {
+	l      net.Listener
+	faces  map[uint64]*AcceptedFace
+	nextID uint64
+	mu     sync.Mutex
+}
+
+// ListenFace listens on a stream network like "tcp" or "unix".
+func ListenFace(network, address string) (*FaceListener, error) {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewFaceListener(l), nil
+}
+
+// NewFaceListener creates a face listener from net.Listener.
+func NewFaceListener(l net.Listener) *FaceListener {
+	return &FaceListener{
+		l:     l,
+		faces: make(map[uint64]*AcceptedFace),
+	}
+}
+
+// Accept waits for the next connection, and returns it as a face.
+func (l *FaceListener) Accept() (*AcceptedFace, error) {
+	conn, err := l.l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	in := make(chan *Interest)
+	out := make(chan *Interest)
+	f := &AcceptedFace{
+		Face: NewFace(conn, in),
+		Recv: out,
+	}
+
+	l.mu.Lock()
+	l.nextID++
+	f.ID = l.nextID
+	l.faces[f.ID] = f
+	l.mu.Unlock()
+
+	go func() {
+		for i := range in {
+			select {
+			case out <- i:
+			case <-f.Done():
+				// interests of a closed face are dropped
+			}
+		}
+		// the transport is closed
+		f.Close()
+		l.mu.Lock()
+		delete(l.faces, f.ID)
+		l.mu.Unlock()
+		close(out)
+	}()
+	return f, nil
+}
+
+// Face returns the face with id if it is not closed.
+func (l *FaceListener) Face(id uint64) (*AcceptedFace, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.faces[id]
+	if !ok || f.closed() {
+		return nil, false
+	}
+	return f, true
+}
+
+// Faces returns all faces that are not closed, in the order of face id.
+func (l *FaceListener) Faces() []*AcceptedFace {
+	l.mu.Lock()
+	faces := make([]*AcceptedFace, 0, len(l.faces))
+	for _, f := range l.faces {
+		if !f.closed() {
+			faces = append(faces, f)
+		}
+	}
+	l.mu.Unlock()
+	sort.Slice(faces, func(i, j int) bool {
+		return faces[i].ID < faces[j].ID
+	})
+	return faces
+}
+
+// Addr returns the listener's network address.
+func (l *FaceListener) Addr() net.Addr {
+	return l.l.Addr()
+}
+
+// Close stops accepting connections, and closes all faces.
+func (l *FaceListener) Close() error {
+	err := l.l.Close()
+	for _, f := range l.Faces() {
+		f.Close()
+	}
+	return err
+}
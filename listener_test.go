@@ -0,0 +1,136 @@
+package ndn
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFaceListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ndn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		network, address string
+	}{
+		{"tcp", "127.0.0.1:0"},
+		{"unix", filepath.Join(dir, "nfd.sock")},
+	} {
+		l, err := ListenFace(test.network, test.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			for {
+				f, err := l.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					for i := range f.Recv {
+						f.SendData(&Data{
+							Name: i.Name,
+						})
+					}
+				}()
+			}
+		}()
+
+		var clients []Face
+		for n := 0; n < 2; n++ {
+			conn, err := net.Dial(l.Addr().Network(), l.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := NewFace(conn, nil)
+			_, err = c.SendInterest(&Interest{
+				Name: NewName("/A"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			clients = append(clients, c)
+		}
+		faces := l.Faces()
+		if len(faces) != 2 || faces[0].ID != 1 || faces[1].ID != 2 {
+			t.Fatalf("expect faces 1 and 2, got %v", faces)
+		}
+
+		// remote close
+		clients[0].Close()
+		select {
+		case <-faces[0].Done():
+		case <-time.After(time.Second):
+			t.Fatal("expect face to be closed")
+		}
+		if _, ok := l.Face(1); ok {
+			t.Fatal("expect closed face to be removed")
+		}
+
+		// listener close
+		l.Close()
+		select {
+		case <-faces[1].Done():
+		case <-time.After(time.Second):
+			t.Fatal("expect face to be closed")
+		}
+		if len(l.Faces()) != 0 {
+			t.Fatal("expect all faces to be removed")
+		}
+		clients[1].Close()
+	}
+}
+
+func TestFaceListenerClose(t *testing.T) {
+	l, err := ListenFace("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	accepted := make(chan *AcceptedFace, 1)
+	go func() {
+		f, err := l.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- f
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewFace(conn, nil)
+	defer c.Close()
+	f := <-accepted
+
+	// Recv is not handled before the face is closed
+	go c.SendInterest(&Interest{
+		Name:     NewName("/A"),
+		LifeTime: 100,
+	})
+	time.Sleep(50 * time.Millisecond)
+	f.Close()
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expect face to be closed")
+	}
+	for deadline := time.Now().Add(time.Second); ; {
+		l.mu.Lock()
+		n := len(l.faces)
+		l.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expect closed face to be released")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
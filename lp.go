@@ -0,0 +1,151 @@
+package ndn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/go-ndn/tlv"
+)
+
+// Errors introduced by LpPacket.
+var (
+	ErrSequence = errors.New("lp sequence must be 8 octets")
+)
+
+// LpPacket is the link protocol packet of NDNLPv2.
+//
+// See https://redmine.named-data.net/projects/nfd/wiki/NDNLPv2.
+type LpPacket struct {
+	Sequence       uint64
+	FragIndex      uint64
+	FragCount      uint64
+	Nack           *Nack
+	CongestionMark uint64
+	Fragment       []byte
+}
+
+// Nack indicates that the interest in Fragment cannot be satisfied.
+type Nack struct {
+	Reason uint64 `tlv:"801?"`
+}
+
+// NackReason specifies why an interest is nacked.
+const (
+	NackReasonNone       uint64 = 0
+	NackReasonCongestion        = 50
+	NackReasonDuplicate         = 100
+	NackReasonNoRoute           = 150
+)
+
+// NackError is returned when an interest is nacked by the remote side.
+type NackError struct {
+	Reason uint64
+}
+
+func (err *NackError) Error() string {
+	var reason string
+	switch err.Reason {
+	case NackReasonNone:
+		reason = "None"
+	case NackReasonCongestion:
+		reason = "Congestion"
+	case NackReasonDuplicate:
+		reason = "Duplicate"
+	case NackReasonNoRoute:
+		reason = "NoRoute"
+	default:
+		reason = fmt.Sprint(err.Reason)
+	}
+	return "nack: " + reason
+}
+
+// WriteTo implements tlv.WriteTo.
+func (p *LpPacket) WriteTo(w tlv.Writer) error {
+	return w.Write(p, 100)
+}
+
+// ReadFrom implements tlv.ReadFrom.
+func (p *LpPacket) ReadFrom(r tlv.Reader) error {
+	return r.Read(p, 100)
+}
+
+// MarshalBinary encodes LpPacket header fields and fragment in tlv.
+//
+// LpPacket needs to implement encoding.BinaryMarshaler
+// because a Nack without reason is still present,
+// and Sequence is a fixed-width number.
+func (p LpPacket) MarshalBinary() ([]byte, error) {
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, p.Sequence)
+	buf := new(bytes.Buffer)
+	w := tlv.NewWriter(buf)
+	for _, field := range []struct {
+		v    interface{}
+		t    uint64
+		omit bool
+	}{
+		{seq, 81, p.Sequence == 0 && p.FragCount <= 1},
+		{p.FragIndex, 82, p.FragIndex == 0},
+		{p.FragCount, 83, p.FragCount <= 1},
+		{p.Nack, 800, p.Nack == nil},
+		{p.CongestionMark, 832, p.CongestionMark == 0},
+		{p.Fragment, 80, len(p.Fragment) == 0},
+	} {
+		if field.omit {
+			continue
+		}
+		err := w.Write(field.v, field.t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes LpPacket in tlv.
+//
+// Unknown header fields are ignored if they are marked as ignorable;
+// otherwise, ErrNotSupported is returned, and the packet should be dropped.
+func (p *LpPacket) UnmarshalBinary(b []byte) error {
+	*p = LpPacket{}
+	r := tlv.NewReader(bytes.NewReader(b))
+	for {
+		var err error
+		switch t := r.Peek(); t {
+		case 81:
+			var seq []byte
+			err = r.Read(&seq, t)
+			if err != nil {
+				return err
+			}
+			if len(seq) != 8 {
+				return ErrSequence
+			}
+			p.Sequence = binary.BigEndian.Uint64(seq)
+		case 82:
+			err = r.Read(&p.FragIndex, t)
+		case 83:
+			err = r.Read(&p.FragCount, t)
+		case 800:
+			p.Nack = new(Nack)
+			err = r.Read(p.Nack, t)
+		case 832:
+			err = r.Read(&p.CongestionMark, t)
+		case 80:
+			err = r.Read(&p.Fragment, t)
+		case 0:
+			return nil
+		default:
+			if t < 800 || t > 959 || t&0x3 != 0 {
+				return ErrNotSupported
+			}
+			var skip []byte
+			err = r.Read(&skip, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
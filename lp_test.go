@@ -0,0 +1,48 @@
+package ndn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLpPacket(t *testing.T) {
+	p := &LpPacket{
+		Sequence:  1,
+		FragIndex: 1,
+		FragCount: 2,
+		Fragment:  []byte{1, 2, 3},
+	}
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sequence is always 8 octets
+	if want := []byte{81, 8, 0, 0, 0, 0, 0, 0, 0, 1}; !bytes.HasPrefix(b, want) {
+		t.Fatalf("expect prefix %v, got %v", want, b)
+	}
+	p2 := new(LpPacket)
+	err = p2.UnmarshalBinary(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, p2) {
+		t.Fatalf("expect %+v, got %+v", p, p2)
+	}
+
+	for _, test := range []struct {
+		in   []byte
+		want error
+	}{
+		{[]byte{81, 1, 1, 80, 1, 1}, ErrSequence},
+		{[]byte{90, 1, 1, 80, 1, 1}, ErrNotSupported},
+		{[]byte{248, 1, 1, 80, 1, 1}, ErrNotSupported},
+		// ignorable unknown header
+		{[]byte{253, 3, 76, 1, 1, 80, 1, 1}, nil},
+	} {
+		err := p2.UnmarshalBinary(test.in)
+		if err != test.want {
+			t.Fatalf("%v: expect %v, got %v", test.in, test.want, err)
+		}
+	}
+}
@@ -2,30 +2,98 @@ package ndn
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-ndn/lpm"
 	"github.com/go-ndn/tlv"
 )
 
+// Errors introduced by Name.
+var (
+	ErrInvalidURI = errors.New("invalid ndn uri")
+)
+
 // Name is a hierarchical name for NDN content, which contains a sequence of name components.
+//
+// Components only hold component values. The TLV-TYPE of each component
+// is tracked separately; see Type. Components appended directly to Components
+// are generic name components.
 type Name struct {
 	Components           []lpm.Component `tlv:"8"`
 	ImplicitDigestSHA256 lpm.Component   `tlv:"1?"`
+
+	// types is either empty if all components are generic,
+	// or holds a TLV-TYPE for each leading component.
+	types []uint64
 }
 
-// NewName creates a name by invoking lpm.NewComponents.
+// NewName creates a name from NDN URI.
+//
+// Invalid components are kept as generic name components.
+// See ParseName.
 func NewName(s string) (n Name) {
-	n.Components = lpm.NewComponents(s)
+	for _, s := range splitURI(s) {
+		t, v, err := parseComponent(s)
+		if err != nil {
+			t, v = ComponentTypeGeneric, lpm.Component(s)
+		}
+		if t == ComponentTypeImplicitSHA256Digest {
+			n.ImplicitDigestSHA256 = v
+			continue
+		}
+		n.push(t, v)
+	}
 	return
 }
 
-// Compare compares two names according to http://named-data.net/doc/ndn-tlv/name.html#canonical-order.
+// ParseName creates a name from NDN URI.
+//
+// See https://named-data.net/doc/NDN-packet-spec/current/name.html#ndn-uri-scheme.
+func ParseName(s string) (n Name, err error) {
+	for _, s := range splitURI(s) {
+		if len(n.ImplicitDigestSHA256) != 0 {
+			err = ErrInvalidURI
+			return
+		}
+		var t uint64
+		var v lpm.Component
+		t, v, err = parseComponent(s)
+		if err != nil {
+			return
+		}
+		if t == ComponentTypeImplicitSHA256Digest {
+			n.ImplicitDigestSHA256 = v
+			continue
+		}
+		n.push(t, v)
+	}
+	return
+}
+
+func splitURI(s string) []string {
+	s = strings.TrimPrefix(s, "ndn:")
+	var comps []string
+	for _, s := range strings.Split(s, "/") {
+		if s == "" {
+			continue
+		}
+		comps = append(comps, s)
+	}
+	return comps
+}
+
+// Compare compares two names according to https://named-data.net/doc/NDN-packet-spec/current/name.html#canonical-order.
 //
 // -1 if a < b; 0 if a == b; 1 if a > b
 func (n *Name) Compare(n2 Name) int {
 	l1, l2 := n.Len(), n2.Len()
 	for i := 0; i < l1 && i < l2; i++ {
-		cmp := bytes.Compare(n.Components[i], n2.Components[i])
+		cmp := compareComponent(n.Type(i), n.Components[i], n2.Type(i), n2.Components[i])
 		if cmp != 0 {
 			return cmp
 		}
@@ -39,11 +107,64 @@ func (n *Name) Compare(n2 Name) int {
 	return 0
 }
 
+func compareComponent(t1 uint64, c1 lpm.Component, t2 uint64, c2 lpm.Component) int {
+	switch {
+	case t1 < t2:
+		return -1
+	case t1 > t2:
+		return 1
+	case len(c1) < len(c2):
+		return -1
+	case len(c1) > len(c2):
+		return 1
+	}
+	return bytes.Compare(c1, c2)
+}
+
 // Len returns the number of components.
 func (n *Name) Len() int {
 	return len(n.Components)
 }
 
+// Type returns the TLV-TYPE of the i-th component.
+func (n *Name) Type(i int) uint64 {
+	if i < len(n.types) {
+		return n.types[i]
+	}
+	return ComponentTypeGeneric
+}
+
+// key returns the key of the name in lpm matchers.
+//
+// Unlike Components, each key component also carries the TLV-TYPE,
+// so that a typed component never collides with a generic one of the same value.
+// ImplicitDigestSHA256 is not included.
+func (n Name) key() []lpm.Component {
+	key := make([]lpm.Component, len(n.Components))
+	for i, c := range n.Components {
+		key[i] = componentKey(n.Type(i), c)
+	}
+	return key
+}
+
+// componentKey prepends the TLV-TYPE in uvarint to the component value.
+func componentKey(t uint64, c lpm.Component) lpm.Component {
+	b := make(lpm.Component, binary.MaxVarintLen64+len(c))
+	n := binary.PutUvarint(b, t)
+	return append(b[:n], c...)
+}
+
+// push appends a component in place.
+func (n *Name) push(t uint64, v lpm.Component) {
+	if t != ComponentTypeGeneric || len(n.types) != 0 {
+		for len(n.types) < len(n.Components) {
+			n.types = append(n.types, ComponentTypeGeneric)
+		}
+		n.types = append(n.types, t)
+	}
+	n.Components = append(n.Components, v)
+}
+
 // WriteTo implements tlv.WriteTo
 func (n *Name) WriteTo(w tlv.Writer) error {
 	return w.Write(n, 7)
@@ -54,11 +175,172 @@ func (n *Name) ReadFrom(r tlv.Reader) error {
 	return r.Read(n, 7)
 }
 
+// MarshalBinary encodes name components in tlv, each with its own TLV-TYPE.
+func (n Name) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := tlv.NewWriter(buf)
+	for i, c := range n.Components {
+		err := w.Write(c, n.Type(i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(n.ImplicitDigestSHA256) != 0 {
+		err := w.Write(n.ImplicitDigestSHA256, ComponentTypeImplicitSHA256Digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes name components in tlv.
+//
+// See MarshalBinary.
+func (n *Name) UnmarshalBinary(b []byte) error {
+	*n = Name{}
+	r := tlv.NewReader(bytes.NewReader(b))
+	for {
+		t := r.Peek()
+		if t == 0 {
+			return nil
+		}
+		if len(n.ImplicitDigestSHA256) != 0 {
+			// implicit digest must be the last component
+			return ErrNotSupported
+		}
+		var c lpm.Component
+		err := r.Read(&c, t)
+		if err != nil {
+			return err
+		}
+		if t == ComponentTypeImplicitSHA256Digest {
+			n.ImplicitDigestSHA256 = c
+			continue
+		}
+		n.push(t, c)
+	}
+}
+
+// String returns the canonical NDN URI of the name.
 func (n Name) String() string {
 	buf := new(bytes.Buffer)
-	for _, c := range n.Components {
+	for i, c := range n.Components {
 		buf.WriteByte('/')
-		buf.WriteString(c.String())
+		writeComponent(buf, n.Type(i), c)
+	}
+	if len(n.ImplicitDigestSHA256) != 0 {
+		buf.WriteByte('/')
+		writeComponent(buf, ComponentTypeImplicitSHA256Digest, n.ImplicitDigestSHA256)
+	}
+	if buf.Len() == 0 {
+		return "/"
 	}
 	return buf.String()
 }
+
+func writeComponent(buf *bytes.Buffer, t uint64, c lpm.Component) {
+	switch t {
+	case ComponentTypeImplicitSHA256Digest:
+		buf.WriteString("sha256digest=")
+		buf.WriteString(hex.EncodeToString(c))
+		return
+	case ComponentTypeParametersSHA256Digest:
+		buf.WriteString("params-sha256=")
+		buf.WriteString(hex.EncodeToString(c))
+		return
+	case ComponentTypeGeneric:
+	default:
+		if alias, ok := componentAlias[t]; ok {
+			if v, err := decodeNumber(c); err == nil {
+				fmt.Fprintf(buf, "%s=%d", alias, v)
+				return
+			}
+		}
+		fmt.Fprintf(buf, "%d=", t)
+	}
+	if len(bytes.Trim(c, ".")) == 0 {
+		buf.WriteString("...")
+	}
+	for _, b := range c {
+		if isUnreserved(b) {
+			buf.WriteByte(b)
+		} else {
+			fmt.Fprintf(buf, "%%%02X", b)
+		}
+	}
+}
+
+func parseComponent(s string) (t uint64, c lpm.Component, err error) {
+	t = ComponentTypeGeneric
+	if pos := strings.IndexByte(s, '='); pos != -1 {
+		prefix, value := s[:pos], s[pos+1:]
+		switch prefix {
+		case "sha256digest":
+			t = ComponentTypeImplicitSHA256Digest
+		case "params-sha256":
+			t = ComponentTypeParametersSHA256Digest
+		}
+		if t != ComponentTypeGeneric {
+			c, err = hex.DecodeString(value)
+			if err != nil || len(c) != 32 {
+				err = ErrInvalidURI
+			}
+			return
+		}
+		for typ, alias := range componentAlias {
+			if alias != prefix {
+				continue
+			}
+			var v uint64
+			v, err = strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				err = ErrInvalidURI
+				return
+			}
+			t, c = typ, encodeNumber(v)
+			return
+		}
+		if typ, err := strconv.ParseUint(prefix, 10, 16); err == nil {
+			if typ == 0 {
+				return 0, nil, ErrInvalidURI
+			}
+			t, s = typ, value
+		}
+	}
+	if len(strings.Trim(s, ".")) == 0 {
+		if len(s) < 3 {
+			return 0, nil, ErrInvalidURI
+		}
+		s = s[3:]
+	}
+	c, err = unescape(s)
+	return
+}
+
+func unescape(s string) (lpm.Component, error) {
+	c := make(lpm.Component, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			c = append(c, s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return nil, ErrInvalidURI
+		}
+		b, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return nil, ErrInvalidURI
+		}
+		c = append(c, b[0])
+		i += 2
+	}
+	return c, nil
+}
+
+func isUnreserved(b byte) bool {
+	return 'a' <= b && b <= 'z' ||
+		'A' <= b && b <= 'Z' ||
+		'0' <= b && b <= '9' ||
+		b == '-' || b == '.' || b == '_' || b == '~'
+}
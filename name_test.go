@@ -1,6 +1,10 @@
 package ndn
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestName(t *testing.T) {
 	name := NewName("/A/B")
@@ -21,3 +25,131 @@ func TestName(t *testing.T) {
 		}
 	}
 }
+
+func TestNameURI(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"/", "/"},
+		{"ndn:/A/B", "/A/B"},
+		{"/hello%20world/%2F", "/hello%20world/%2F"},
+		{"/8=A/....", "/A/...."},
+		{"/.../.....", "/.../....."},
+		{"/seg=1/v=1500000000/off=10/t=42/seq=7", "/seg=1/v=1500000000/off=10/t=42/seq=7"},
+		{"/50=%01", "/seg=1"},
+		{"/32=metadata/252=A", "/32=metadata/252=A"},
+		{
+			"/A/sha256digest=b8583bf24fd0cd1a64b671c7677f0989f4efad549a93dc7e5231aa1899965095",
+			"/A/sha256digest=b8583bf24fd0cd1a64b671c7677f0989f4efad549a93dc7e5231aa1899965095",
+		},
+	} {
+		name, err := ParseName(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := name.String()
+		if got != test.want {
+			t.Fatalf("ParseName(%v) == %v, got %v", test.in, test.want, got)
+		}
+		name2, err := ParseName(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name.Compare(name2) != 0 || !reflect.DeepEqual(name, name2) {
+			t.Fatalf("expect %+v, got %+v", name, name2)
+		}
+	}
+
+	for _, in := range []string{
+		"/..",
+		"/seg=A",
+		"/%4",
+		"/0=A",
+		"/sha256digest=00",
+	} {
+		_, err := ParseName(in)
+		if err != ErrInvalidURI {
+			t.Fatalf("ParseName(%v) should fail", in)
+		}
+	}
+}
+
+func TestNameComponentType(t *testing.T) {
+	now := time.Unix(1500000000, 123000)
+	name := NewName("/A").
+		AppendVersion(3).
+		AppendSegment(1000).
+		AppendTimestamp(now).
+		AppendSequenceNumber(7).
+		AppendByteOffset(1 << 40)
+	if got := name.String(); got != "/A/v=3/seg=1000/t=1500000000000123/seq=7/off=1099511627776" {
+		t.Fatalf("unexpected name %v", got)
+	}
+	for i, test := range []struct {
+		get  func(*Name) (uint64, bool)
+		want uint64
+	}{
+		{(*Name).Version, 3},
+		{(*Name).Segment, 1000},
+		{func(n *Name) (uint64, bool) {
+			ts, ok := n.Timestamp()
+			return uint64(ts.UnixNano()), ok
+		}, uint64(now.UnixNano())},
+		{(*Name).SequenceNumber, 7},
+		{(*Name).ByteOffset, 1 << 40},
+	} {
+		prefix := name.Prefix(i + 2)
+		got, ok := test.get(&prefix)
+		if !ok || got != test.want {
+			t.Fatalf("%v: expect %v, got %v", prefix, test.want, got)
+		}
+	}
+	if _, ok := name.Segment(); ok {
+		t.Fatalf("%v should not end with segment", name)
+	}
+	for _, test := range []struct {
+		in   Name
+		l    int
+		want Name
+	}{
+		{NewName("/A/B"), 1, NewName("/A")},
+		{name, 1, NewName("/A")},
+		{name, 2, NewName("/A/v=3")},
+	} {
+		if got := test.in.Prefix(test.l); !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("expect %+v, got %+v", test.want, got)
+		}
+	}
+	// typed and generic components with the same value are different keys
+	if k1, k2 := NewName("/A/%01").key(), NewName("/A/seg=1").key(); reflect.DeepEqual(k1, k2) {
+		t.Fatalf("expect different keys, got %v", k1)
+	}
+
+	b, err := name.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name2 Name
+	err = name2.UnmarshalBinary(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(name, name2) {
+		t.Fatalf("expect %+v, got %+v", name, name2)
+	}
+
+	// generic components sort before typed components, and shorter ones first
+	for _, test := range []struct {
+		a, b Name
+	}{
+		{NewName("/A/ZZ"), NewName("/A").AppendSegment(0)},
+		{NewName("/A/B"), NewName("/A/AA")},
+		{NewName("/A").AppendSegment(9), NewName("/A").AppendSegment(10)},
+		{NewName("/A").AppendSegment(255), NewName("/A").AppendSegment(256)},
+	} {
+		if test.a.Compare(test.b) != -1 || test.b.Compare(test.a) != 1 {
+			t.Fatalf("expect %v < %v", test.a, test.b)
+		}
+	}
+}
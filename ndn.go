@@ -7,20 +7,70 @@ package ndn
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"hash"
 	"hash/crc32"
 	"math/rand"
+	"reflect"
 
 	"github.com/go-ndn/lpm"
 	"github.com/go-ndn/tlv"
 )
 
+// Errors introduced by packet encoding.
+var (
+	ErrParametersDigest = errors.New("parameters digest mismatch")
+	ErrSelectors        = errors.New("selectors cannot be encoded in v0.3")
+	ErrHopLimit         = errors.New("hop limit must be one octet")
+)
+
 // Interest carries a name that identifies the desired data.
+//
+// Both NDN packet format v0.2 and v0.3 are decoded by ReadFrom.
+// WriteTo encodes v0.3 unless v0.2 selectors require v0.2;
+// use WriteToFormat to choose the format explicitly.
+//
+// An interest that carries v0.2 Selectors keeps v0.2 semantics, which
+// allow any data under its name to match. Otherwise, only data with exactly
+// the same name matches unless CanBePrefix is set.
 type Interest struct {
-	Name      Name      `tlv:"7"`
-	Selectors Selectors `tlv:"9?"`
-	Nonce     uint64    `tlv:"10"`
-	LifeTime  uint64    `tlv:"12?"`
+	Name                  Name           `tlv:"7"`
+	Selectors             Selectors      `tlv:"9?"`
+	CanBePrefix           bool           `tlv:"33?"`
+	MustBeFresh           bool           `tlv:"18?"`
+	ForwardingHint        ForwardingHint `tlv:"30?"`
+	Nonce                 []byte         `tlv:"10"`
+	LifeTime              uint64         `tlv:"12?"`
+	HopLimit              *uint8         `tlv:"34?"` // nil means absent
+	ApplicationParameters []byte         `tlv:"36?"`
+	// SignatureInfo is not nil if the interest is signed.
+	SignatureInfo  *InterestSignatureInfo `tlv:"44?"`
+	SignatureValue []byte                 `tlv:"46?*"`
+}
+
+// InterestFormat specifies the packet format generation of an interest.
+type InterestFormat int
+
+// InterestFormat enumeration.
+const (
+	InterestFormatV03 InterestFormat = iota
+	InterestFormatV02
+)
+
+// interestV02 is the wire layout of an interest in NDN packet format v0.2.
+type interestV02 struct {
+	Name           Name           `tlv:"7"`
+	Selectors      Selectors      `tlv:"9?"`
+	Nonce          []byte         `tlv:"10"`
+	LifeTime       uint64         `tlv:"12?"`
+	ForwardingHint ForwardingHint `tlv:"30?"`
+}
+
+// ForwardingHint is a list of delegation names that helps forwarders
+// to reach the producer when the interest name itself is not routable.
+type ForwardingHint struct {
+	Names []Name `tlv:"7"`
 }
 
 // Selectors are optional elements that further qualify Data that may match the Interest.
@@ -83,8 +133,69 @@ type MetaInfo struct {
 // It should be present in the final block itself, and may also be present in other
 // fragments to provide advanced warning of the end to consumers.
 // The value here should be equal to the last explicit Name Component of the final block.
+//
+// Component is a generic name component unless FinalBlockID is created with NewFinalBlockID.
 type FinalBlockID struct {
 	Component lpm.Component `tlv:"8"`
+	typ       uint64
+}
+
+// NewFinalBlockID creates FinalBlockID from the last component of the final block name.
+func NewFinalBlockID(n Name) (id FinalBlockID) {
+	l := n.Len()
+	if l == 0 {
+		return
+	}
+	id.Component = n.Components[l-1]
+	if t := n.Type(l - 1); t != ComponentTypeGeneric {
+		id.typ = t
+	}
+	return
+}
+
+// Type returns the TLV-TYPE of the component.
+func (id *FinalBlockID) Type() uint64 {
+	if id.typ == 0 {
+		return ComponentTypeGeneric
+	}
+	return id.typ
+}
+
+// Segment returns the segment number if the final block is identified by segment.
+func (id *FinalBlockID) Segment() (uint64, bool) {
+	if id.Type() != ComponentTypeSegment {
+		return 0, false
+	}
+	v, err := decodeNumber(id.Component)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// MarshalBinary encodes the component of FinalBlockID with its TLV-TYPE.
+func (id FinalBlockID) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := tlv.NewWriter(buf).Write(id.Component, id.Type())
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes FinalBlockID in tlv.
+func (id *FinalBlockID) UnmarshalBinary(b []byte) error {
+	r := tlv.NewReader(bytes.NewReader(b))
+	t := r.Peek()
+	err := r.Read(&id.Component, t)
+	if err != nil {
+		return err
+	}
+	id.typ = t
+	if t == ComponentTypeGeneric {
+		id.typ = 0
+	}
+	return nil
 }
 
 // CompressionType specifies compression algorithm for data packets.
@@ -115,6 +226,16 @@ type SignatureInfo struct {
 	ValidityPeriod ValidityPeriod `tlv:"253?"`
 }
 
+// InterestSignatureInfo describes the signature of a signed interest,
+// and carries elements for replay protection.
+type InterestSignatureInfo struct {
+	SignatureType   uint64     `tlv:"27"`
+	KeyLocator      KeyLocator `tlv:"28?"`
+	SignatureNonce  []byte     `tlv:"38?"`
+	SignatureTime   uint64     `tlv:"40?"` // milliseconds since Unix epoch; zero means absent
+	SignatureSeqNum uint64     `tlv:"42?"` // zero means absent
+}
+
 // SignatureType specifies signing algorithm for data packets.
 const (
 	SignatureTypeDigestSHA256    uint64 = 0
@@ -122,6 +243,7 @@ const (
 	SignatureTypeDigestCRC32C           = 2
 	SignatureTypeSHA256WithECDSA        = 3
 	SignatureTypeSHA256WithHMAC         = 4
+	SignatureTypeEd25519                = 5
 )
 
 // KeyLocator specifies either Name that points to another Data packet containing
@@ -144,17 +266,283 @@ type ValidityPeriod struct {
 
 // WriteTo implements tlv.WriteTo.
 //
-// Nonce will be populated if it is empty.
+// It is equivalent to WriteToFormat with InterestFormatV03,
+// so it also updates Nonce and Name of the interest.
+// However, if the interest carries selectors other than MustBeFresh,
+// which usually means that it is decoded from a v0.2 peer, it is encoded in v0.2
+// instead, so that it can still be forwarded without losing them.
 func (i *Interest) WriteTo(w tlv.Writer) error {
-	if i.Nonce == 0 {
-		i.Nonce = uint64(rand.Uint32())
+	if i.hasV02Selectors() {
+		return i.WriteToFormat(w, InterestFormatV02)
+	}
+	return i.WriteToFormat(w, InterestFormatV03)
+}
+
+// hasV02Selectors checks whether Selectors cannot be expressed in v0.3.
+func (i *Interest) hasV02Selectors() bool {
+	sel := i.Selectors
+	sel.MustBeFresh = false
+	return !reflect.DeepEqual(sel, Selectors{})
+}
+
+// WriteToFormat encodes the interest in the given packet format.
+//
+// The interest itself is modified, so that the caller sees what is sent:
+// Nonce will be populated if it is empty, and in v0.3,
+// ParametersSha256DigestComponent will be appended to Name if ApplicationParameters is not empty
+// or the interest is signed.
+//
+// In v0.3, Selectors are dropped after MustBeFresh is moved out, and CanBePrefix is set
+// to keep v0.2 semantics. ErrSelectors is returned if any other selector is present,
+// because it cannot be expressed in v0.3.
+// In v0.2, fields introduced by v0.3 are dropped after MustBeFresh is moved into Selectors.
+func (i *Interest) WriteToFormat(w tlv.Writer, format InterestFormat) error {
+	if format == InterestFormatV03 && i.hasV02Selectors() {
+		return ErrSelectors
+	}
+	i.updateNonce()
+	switch format {
+	case InterestFormatV03:
+		err := i.updateParametersDigest()
+		if err != nil {
+			return err
+		}
+		v03 := *i
+		if !reflect.DeepEqual(v03.Selectors, Selectors{}) {
+			v03.CanBePrefix = true
+			v03.MustBeFresh = v03.MustBeFresh || v03.Selectors.MustBeFresh
+			v03.Selectors = Selectors{}
+		}
+		b, err := v03.marshalV03()
+		if err != nil {
+			return err
+		}
+		return w.Write(b, 5)
+	case InterestFormatV02:
+		v02 := &interestV02{
+			Name:           i.Name,
+			Selectors:      i.Selectors,
+			Nonce:          i.Nonce,
+			LifeTime:       i.LifeTime,
+			ForwardingHint: i.ForwardingHint,
+		}
+		v02.Name = v02.Name.withoutParametersSHA256()
+		v02.Selectors.MustBeFresh = v02.Selectors.MustBeFresh || i.MustBeFresh
+		return w.Write(v02, 5)
+	default:
+		return ErrNotSupported
+	}
+}
+
+// marshalV03 encodes elements of the interest in v0.3.
+//
+// Elements are encoded one by one like LpPacket, because ApplicationParameters
+// is still present in a signed interest even if it is empty.
+func (i *Interest) marshalV03() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := tlv.NewWriter(buf)
+	for _, field := range []struct {
+		v    interface{}
+		t    uint64
+		omit bool
+	}{
+		{i.Name, 7, false},
+		{i.CanBePrefix, 33, !i.CanBePrefix},
+		{i.MustBeFresh, 18, !i.MustBeFresh},
+		{i.ForwardingHint, 30, len(i.ForwardingHint.Names) == 0},
+		{i.Nonce, 10, false},
+		{i.LifeTime, 12, i.LifeTime == 0},
+		{i.hopLimit(), 34, i.HopLimit == nil},
+		{i.ApplicationParameters, 36, !i.hasParameters()},
+		{i.SignatureInfo, 44, i.SignatureInfo == nil},
+		{i.SignatureValue, 46, i.SignatureInfo == nil},
+	} {
+		if field.omit {
+			continue
+		}
+		err := w.Write(field.v, field.t)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return w.Write(i, 5)
+	return buf.Bytes(), nil
 }
 
 // ReadFrom implements tlv.ReadFrom.
+//
+// Elements of both v0.2 and v0.3 are accepted in any order.
+// Unrecognized non-critical elements are ignored.
+// If ApplicationParameters is present, ParametersSha256DigestComponent of Name is verified,
+// but the signature is not.
 func (i *Interest) ReadFrom(r tlv.Reader) error {
-	return r.Read(i, 5)
+	var b []byte
+	err := r.Read(&b, 5)
+	if err != nil {
+		return err
+	}
+	*i = Interest{}
+	r = tlv.NewReader(bytes.NewReader(b))
+	for {
+		switch t := r.Peek(); t {
+		case 7:
+			err = r.Read(&i.Name, t)
+		case 9:
+			err = r.Read(&i.Selectors, t)
+		case 33:
+			err = r.Read(&i.CanBePrefix, t)
+		case 18:
+			err = r.Read(&i.MustBeFresh, t)
+		case 30:
+			err = r.Read(&i.ForwardingHint, t)
+		case 10:
+			err = r.Read(&i.Nonce, t)
+		case 12:
+			err = r.Read(&i.LifeTime, t)
+		case 34:
+			err = i.readHopLimit(r)
+		case 36:
+			err = r.Read(&i.ApplicationParameters, t)
+		case 44:
+			i.SignatureInfo = new(InterestSignatureInfo)
+			err = r.Read(i.SignatureInfo, t)
+		case 46:
+			err = r.Read(&i.SignatureValue, t)
+		case 0:
+			i.MustBeFresh = i.MustBeFresh || i.Selectors.MustBeFresh
+			if i.hasParameters() {
+				digest, err := i.parametersDigest()
+				if err != nil {
+					return err
+				}
+				if nameDigest, _ := i.Name.ParametersSHA256(); !bytes.Equal(digest, nameDigest) {
+					return ErrParametersDigest
+				}
+			}
+			return nil
+		default:
+			if isCritical(t) {
+				return ErrNotSupported
+			}
+			var skip []byte
+			err = r.Read(&skip, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// updateNonce populates Nonce if it is empty.
+func (i *Interest) updateNonce() {
+	if len(i.Nonce) == 0 {
+		i.Nonce = make([]byte, 4)
+		binary.BigEndian.PutUint32(i.Nonce, rand.Uint32())
+	}
+}
+
+// hopLimit encodes HopLimit, which is always one octet unlike other numbers.
+func (i *Interest) hopLimit() []byte {
+	if i.HopLimit == nil {
+		return nil
+	}
+	return []byte{*i.HopLimit}
+}
+
+func (i *Interest) readHopLimit(r tlv.Reader) error {
+	var b []byte
+	err := r.Read(&b, 34)
+	if err != nil {
+		return err
+	}
+	if len(b) != 1 {
+		return ErrHopLimit
+	}
+	i.HopLimit = &b[0]
+	return nil
+}
+
+// isCritical checks whether an unrecognized element must fail decoding.
+//
+// See https://named-data.net/doc/NDN-packet-spec/current/tlv.html#considerations-for-evolvability-of-tlv-based-encoding.
+func isCritical(t uint64) bool {
+	return t <= 31 || t%2 == 1
+}
+
+// hasParameters checks whether ApplicationParameters is present.
+func (i *Interest) hasParameters() bool {
+	return len(i.ApplicationParameters) != 0 || i.SignatureInfo != nil
+}
+
+// parametersDigest computes ParametersSha256DigestComponent from ApplicationParameters
+// and signature.
+func (i *Interest) parametersDigest() (lpm.Component, error) {
+	h := sha256.New()
+	w := tlv.NewWriter(h)
+	err := w.Write(i.ApplicationParameters, 36)
+	if err != nil {
+		return nil, err
+	}
+	if i.SignatureInfo != nil {
+		err = w.Write(i.SignatureInfo, 44)
+		if err != nil {
+			return nil, err
+		}
+		err = w.Write(i.SignatureValue, 46)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// updateParametersDigest appends ParametersSha256DigestComponent to Name
+// if ApplicationParameters is present.
+func (i *Interest) updateParametersDigest() error {
+	if !i.hasParameters() {
+		return nil
+	}
+	digest, err := i.parametersDigest()
+	if err != nil {
+		return err
+	}
+	i.Name.setParametersSHA256(digest)
+	return nil
+}
+
+// signedInterest is the signed portion of a signed interest.
+type signedInterest struct {
+	*Interest
+}
+
+func (i signedInterest) signedPortion() ([]byte, error) {
+	b, err := i.Name.withoutParametersSHA256().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(b)
+	w := tlv.NewWriter(buf)
+	err = w.Write(i.ApplicationParameters, 36)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Write(i.SignatureInfo, 44)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Match checks whether the data packet satisfies the interest.
+//
+// The data name must start with the interest name.
+// ChildSelector and freshness are not handled.
+func (i *Interest) Match(d *Data) bool {
+	interestLen := i.Name.Len()
+	if !i.CanBePrefix && d.Name.Len() != interestLen &&
+		reflect.DeepEqual(i.Selectors, Selectors{}) {
+		return false
+	}
+	return i.Selectors.Match(d, interestLen)
 }
 
 var (
@@ -3,6 +3,7 @@ package ndn
 import (
 	"bytes"
 	"io/ioutil"
+	"reflect"
 	"testing"
 
 	"github.com/go-ndn/tlv"
@@ -15,6 +16,133 @@ var (
 	discard = tlv.NewWriter(ioutil.Discard)
 )
 
+func TestInterestFormat(t *testing.T) {
+	hopLimit, zeroHopLimit := uint8(64), uint8(0)
+	for _, test := range []struct {
+		format InterestFormat
+		in     *Interest
+		want   *Interest
+	}{
+		{
+			format: InterestFormatV03,
+			in: &Interest{
+				Name:        NewName("/A/B"),
+				CanBePrefix: true,
+				MustBeFresh: true,
+				ForwardingHint: ForwardingHint{
+					Names: []Name{NewName("/C")},
+				},
+				LifeTime:              1000,
+				HopLimit:              &hopLimit,
+				ApplicationParameters: []byte("params"),
+			},
+		},
+		{
+			format: InterestFormatV03,
+			in: &Interest{
+				Name: NewName("/A"),
+				Selectors: Selectors{
+					MustBeFresh: true,
+				},
+			},
+			want: &Interest{
+				Name:        NewName("/A"),
+				CanBePrefix: true,
+				MustBeFresh: true,
+			},
+		},
+		{
+			format: InterestFormatV03,
+			in: &Interest{
+				Name:     NewName("/A"),
+				HopLimit: &zeroHopLimit,
+			},
+		},
+		{
+			format: InterestFormatV03,
+			in: &Interest{
+				Name:     NewName("/A"),
+				HopLimit: &hopLimit,
+				SignatureInfo: &InterestSignatureInfo{
+					SignatureType:   SignatureTypeDigestSHA256,
+					SignatureNonce:  []byte{1, 2, 3, 4},
+					SignatureTime:   1000,
+					SignatureSeqNum: 1,
+				},
+				ApplicationParameters: []byte("params"),
+				SignatureValue:        []byte{5, 6, 7, 8},
+			},
+		},
+		{
+			format: InterestFormatV02,
+			in: &Interest{
+				Name:        NewName("/A"),
+				MustBeFresh: true,
+				HopLimit:    &hopLimit,
+				LifeTime:    1000,
+			},
+			want: &Interest{
+				Name: NewName("/A"),
+				Selectors: Selectors{
+					MustBeFresh: true,
+				},
+				MustBeFresh: true,
+				LifeTime:    1000,
+			},
+		},
+	} {
+		buf := new(bytes.Buffer)
+		err := test.in.WriteToFormat(tlv.NewWriter(buf), test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(test.in.Nonce) != 4 {
+			t.Fatalf("expect 4-byte nonce, got %v", test.in.Nonce)
+		}
+		want := test.want
+		if want == nil {
+			want = test.in
+		}
+		want.Nonce = test.in.Nonce
+		want.Name = test.in.Name
+
+		got := new(Interest)
+		err = got.ReadFrom(tlv.NewReader(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("expect %+v, got %+v", want, got)
+		}
+	}
+
+	v02 := &Interest{
+		Name: NewName("/A"),
+		Selectors: Selectors{
+			ChildSelector: 1,
+		},
+	}
+	err := v02.WriteToFormat(discard, InterestFormatV03)
+	if err != ErrSelectors {
+		t.Fatalf("expect %v, got %v", ErrSelectors, err)
+	}
+
+	// v0.2 selectors are kept by WriteTo
+	buf := new(bytes.Buffer)
+	err = v02.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(Interest)
+	err = got.ReadFrom(tlv.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v02, got) {
+		t.Fatalf("expect %+v, got %+v", v02, got)
+	}
+}
+
 func BenchmarkDataEncodeRSA(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		err := SignData(rsaKey, data)
@@ -54,6 +182,19 @@ func BenchmarkDataEncodeHMAC(b *testing.B) {
 	}
 }
 
+func BenchmarkDataEncodeEd25519(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		err := SignData(ed25519Key, data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		err = data.WriteTo(discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkDataEncode(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		err := data.WriteTo(discard)
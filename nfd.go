@@ -2,9 +2,8 @@ package ndn
 
 import (
 	"errors"
-	"math/rand"
-	"time"
 
+	"github.com/go-ndn/lpm"
 	"github.com/go-ndn/tlv"
 )
 
@@ -16,6 +15,10 @@ var (
 
 // Command alters forwarder state.
 //
+// It is the name of a command interest in the deprecated v0.2 signed interest format.
+//
+// Deprecated: SendControl signs command interests in v0.3 format.
+//
 // See http://redmine.named-data.net/projects/nfd/wiki/Management.
 type Command struct {
 	Local          string                  `tlv:"8"`
@@ -56,8 +59,10 @@ type Parameters struct {
 	Name             Name     `tlv:"7?"`
 	FaceID           uint64   `tlv:"105?"`
 	URI              string   `tlv:"114?"`
+	LocalURI         string   `tlv:"129?"`
 	Origin           uint64   `tlv:"111?"`
 	Cost             uint64   `tlv:"106?"`
+	Capacity         uint64   `tlv:"131?"`
 	Flags            uint64   `tlv:"108?"`
 	Mask             uint64   `tlv:"112?"`
 	Strategy         Strategy `tlv:"107?"`
@@ -81,20 +86,22 @@ type CommandResponse struct {
 
 // ForwarderStatus is not available in go-nfd.
 type ForwarderStatus struct {
-	NFDVersion       string `tlv:"128"`
-	StartTimestamp   uint64 `tlv:"129"`
-	CurrentTimestamp uint64 `tlv:"130"`
-	NameTreeEntry    uint64 `tlv:"131"`
-	FIBEntry         uint64 `tlv:"132"`
-	PITEntry         uint64 `tlv:"133"`
-	MeasurementEntry uint64 `tlv:"134"`
-	CSEntry          uint64 `tlv:"135"`
-	InInterest       uint64 `tlv:"144"`
-	InData           uint64 `tlv:"145"`
-	InNack           uint64 `tlv:"151"`
-	OutInterest      uint64 `tlv:"146"`
-	OutData          uint64 `tlv:"147"`
-	OutNack          uint64 `tlv:"152"`
+	NFDVersion          string `tlv:"128"`
+	StartTimestamp      uint64 `tlv:"129"`
+	CurrentTimestamp    uint64 `tlv:"130"`
+	NameTreeEntry       uint64 `tlv:"131"`
+	FIBEntry            uint64 `tlv:"132"`
+	PITEntry            uint64 `tlv:"133"`
+	MeasurementEntry    uint64 `tlv:"134"`
+	CSEntry             uint64 `tlv:"135"`
+	InInterest          uint64 `tlv:"144"`
+	InData              uint64 `tlv:"145"`
+	InNack              uint64 `tlv:"151"`
+	OutInterest         uint64 `tlv:"146"`
+	OutData             uint64 `tlv:"147"`
+	OutNack             uint64 `tlv:"152"`
+	SatisfiedInterest   uint64 `tlv:"153?"`
+	UnsatisfiedInterest uint64 `tlv:"154?"`
 }
 
 // FaceStatus is not available in go-nfd.
@@ -152,41 +159,46 @@ type StrategyChoice struct {
 
 // SendControl sends command and waits for its response.
 //
+// The command is a signed interest named /localhost/nfd/<module>/<command>/<ControlParameters>.
 // ErrResponseStatus is returned if the status code is not 200.
 func SendControl(w Sender, module, command string, params *Parameters, key Key) error {
-	cmd := &Command{
-		Local:     "localhost",
-		NFD:       "nfd",
-		Module:    module,
-		Command:   command,
-		Timestamp: uint64(time.Now().UnixNano() / 1000000),
-		Nonce:     uint64(rand.Uint32()),
-	}
-	var err error
-	cmd.Parameters.Parameters = *params
-	cmd.SignatureInfo.SignatureInfo.SignatureType = key.SignatureType()
-	cmd.SignatureInfo.SignatureInfo.KeyLocator.Name = key.Locator()
-	cmd.SignatureValue.SignatureValue, err = key.Sign(cmd)
+	resp, err := sendControl(w, module, command, params, key)
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode != 200 {
+		return ErrResponseStatus
+	}
+	return nil
+}
 
-	i := new(Interest)
-	err = tlv.Copy(&i.Name, cmd)
+// sendControl sends command and decodes its response.
+func sendControl(w Sender, module, command string, params *Parameters, key Key) (*CommandResponse, error) {
+	b, err := tlv.Marshal(params, 104)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	i := &Interest{
+		Name: NewName("/localhost/nfd").
+			Append(ComponentTypeGeneric, lpm.Component(module)).
+			Append(ComponentTypeGeneric, lpm.Component(command)).
+			Append(ComponentTypeGeneric, b),
+	}
+	err = SignInterest(key, i)
+	if err != nil {
+		return nil, err
 	}
 	d, err := w.SendInterest(i)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var resp CommandResponse
-	err = tlv.Unmarshal(d.Content, &resp, 101)
+	resp := new(CommandResponse)
+	err = tlv.Unmarshal(d.Content, resp, 101)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return ErrResponseStatus
+	if r, ok := w.(controlRecorder); ok && resp.StatusCode == 200 {
+		r.recordControl(module, command, params, key)
 	}
-	return nil
+	return resp, nil
 }
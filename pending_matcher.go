@@ -0,0 +1,114 @@
+package ndn
+
+import (
+	"github.com/go-ndn/lpm"
+)
+
+type pendingMatcher struct{ pendingNode }
+type pendingNode struct {
+	val   *[]*forwarderEntry
+	table map[string]*pendingNode
+}
+
+func (n *pendingNode) Empty() bool {
+	return n.val == nil && len(n.table) == 0
+}
+func pendingDeref(val *[]*forwarderEntry) ([]*forwarderEntry, bool) {
+	if val == nil {
+		var t []*forwarderEntry
+		return t, false
+	}
+	return *val, true
+}
+func (n *pendingNode) Match(key []lpm.Component) (val []*forwarderEntry, found bool) {
+	if len(key) == 0 {
+		return pendingDeref(n.val)
+	}
+	if n.table == nil {
+		return pendingDeref(n.val)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return pendingDeref(n.val)
+	}
+	return child.Match(key[1:])
+}
+func (n *pendingNode) Get(key []lpm.Component) (val []*forwarderEntry, found bool) {
+	if len(key) == 0 {
+		return pendingDeref(n.val)
+	}
+	if n.table == nil {
+		return pendingDeref(nil)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return pendingDeref(nil)
+	}
+	return child.Get(key[1:])
+}
+func (n *pendingNode) Update(key []lpm.Component, val []*forwarderEntry) {
+	if len(key) == 0 {
+		n.val = &val
+		return
+	}
+	if n.table == nil {
+		n.table = make(map[string]*pendingNode)
+	}
+	if _, ok := n.table[string(key[0])]; !ok {
+		n.table[string(key[0])] = &pendingNode{}
+	}
+	n.table[string(key[0])].Update(key[1:], val)
+}
+func (n *pendingNode) Delete(key []lpm.Component) {
+	if len(key) == 0 {
+		n.val = nil
+		return
+	}
+	if n.table == nil {
+		return
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return
+	}
+	child.Delete(key[1:])
+	if child.Empty() {
+		delete(n.table, string(key[0]))
+	}
+}
+
+type pendingUpdateFunc func([]lpm.Component, []*forwarderEntry) (val []*forwarderEntry, del bool)
+
+func (n *pendingNode) UpdateAll(key []lpm.Component, f pendingUpdateFunc) {
+	for i := len(key); i > 0; i-- {
+		k := key[:i]
+		val, _ := n.Get(k)
+		val2, del := f(k, val)
+		if !del {
+			n.Update(k, val2)
+		} else {
+			n.Delete(k)
+		}
+	}
+}
+func (n *pendingNode) visit(key []lpm.Component, f func([]lpm.Component)) {
+	for k, v := range n.table {
+		v.visit(append(key, lpm.Component(k)), f)
+	}
+	if n.val != nil {
+		f(key)
+	}
+}
+func (n *pendingNode) Visit(f pendingUpdateFunc) {
+	n.visit(make([]lpm.Component, 0, 16), func(k []lpm.Component) {
+		val, found := n.Get(k)
+		if found {
+			val2, del := f(k, val)
+			if !del {
+				n.Update(k, val2)
+			} else {
+				n.Delete(k)
+			}
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package ndn
+
+import (
+	"container/heap"
+	"container/list"
+	"math"
+	"time"
+)
+
+// Policy decides which data packet is evicted from a content store.
+//
+// Entries are identified by unique keys chosen by the content store.
+// Policy is not required to be thread-safe.
+type Policy interface {
+	// Add is invoked when a new entry is inserted.
+	Add(key string, d *Data)
+	// Hit is invoked when an existing entry is inserted again or found.
+	Hit(key string)
+	// Remove is invoked when an entry is removed regardless of policy.
+	Remove(key string)
+	// Evict chooses an entry to evict, and forgets it.
+	// It returns an empty key if there is no entry.
+	Evict() string
+}
+
+// NewLRU creates a least-recently-used replacement policy.
+func NewLRU() Policy {
+	return &lru{
+		List:  list.New(),
+		index: make(map[string]*list.Element),
+	}
+}
+
+type lru struct {
+	*list.List
+	index map[string]*list.Element
+}
+
+func (p *lru) Add(key string, _ *Data) {
+	if elem, ok := p.index[key]; ok {
+		p.MoveToFront(elem)
+		return
+	}
+	p.index[key] = p.PushFront(key)
+}
+
+func (p *lru) Hit(key string) {
+	if elem, ok := p.index[key]; ok {
+		p.MoveToFront(elem)
+	}
+}
+
+func (p *lru) Remove(key string) {
+	if elem, ok := p.index[key]; ok {
+		p.List.Remove(elem)
+		delete(p.index, key)
+	}
+}
+
+func (p *lru) Evict() string {
+	elem := p.Back()
+	if elem == nil {
+		return ""
+	}
+	key := p.List.Remove(elem).(string)
+	delete(p.index, key)
+	return key
+}
+
+// NewFIFO creates a first-in-first-out replacement policy.
+func NewFIFO() Policy {
+	return &fifo{
+		List:  list.New(),
+		index: make(map[string]*list.Element),
+	}
+}
+
+type fifo struct {
+	*list.List
+	index map[string]*list.Element
+}
+
+func (p *fifo) Add(key string, _ *Data) {
+	if _, ok := p.index[key]; ok {
+		return
+	}
+	p.index[key] = p.PushBack(key)
+}
+
+func (p *fifo) Hit(string) {}
+
+func (p *fifo) Remove(key string) {
+	if elem, ok := p.index[key]; ok {
+		p.List.Remove(elem)
+		delete(p.index, key)
+	}
+}
+
+func (p *fifo) Evict() string {
+	elem := p.Front()
+	if elem == nil {
+		return ""
+	}
+	key := p.List.Remove(elem).(string)
+	delete(p.index, key)
+	return key
+}
+
+// priorityItem is an entry of priority-based policies.
+type priorityItem struct {
+	key      string
+	priority int64
+	seq      uint64 // breaks ties in insertion order
+	index    int
+}
+
+// priorityQueue is a min-heap of priorityItem.
+type priorityQueue []*priorityItem
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *priorityQueue) Push(x interface{}) {
+	item := x.(*priorityItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// priority is a policy that evicts the entry with the lowest priority.
+type priority struct {
+	queue priorityQueue
+	index map[string]*priorityItem
+	seq   uint64
+}
+
+func (p *priority) push(key string, prio int64) {
+	p.seq++
+	item := &priorityItem{
+		key:      key,
+		priority: prio,
+		seq:      p.seq,
+	}
+	heap.Push(&p.queue, item)
+	p.index[key] = item
+}
+
+func (p *priority) Remove(key string) {
+	if item, ok := p.index[key]; ok {
+		heap.Remove(&p.queue, item.index)
+		delete(p.index, key)
+	}
+}
+
+func (p *priority) Evict() string {
+	if len(p.queue) == 0 {
+		return ""
+	}
+	item := heap.Pop(&p.queue).(*priorityItem)
+	delete(p.index, item.key)
+	return item.key
+}
+
+// NewLFU creates a least-frequently-used replacement policy.
+//
+// Entries with the same frequency are evicted in insertion order.
+func NewLFU() Policy {
+	return &lfu{
+		priority: priority{
+			index: make(map[string]*priorityItem),
+		},
+	}
+}
+
+type lfu struct {
+	priority
+}
+
+func (p *lfu) Add(key string, _ *Data) {
+	p.push(key, 1)
+}
+
+func (p *lfu) Hit(key string) {
+	if item, ok := p.index[key]; ok {
+		item.priority++
+		heap.Fix(&p.queue, item.index)
+	}
+}
+
+// NewFreshness creates a replacement policy that evicts the entry
+// that becomes stale first, according to its FreshnessPeriod.
+//
+// Entries without FreshnessPeriod never become stale like in Cache.Get,
+// so they are evicted last.
+func NewFreshness() Policy {
+	return &freshness{
+		priority: priority{
+			index: make(map[string]*priorityItem),
+		},
+	}
+}
+
+type freshness struct {
+	priority
+}
+
+func (p *freshness) Add(key string, d *Data) {
+	stale, ok := staleTime(d, time.Now())
+	if !ok {
+		p.push(key, math.MaxInt64)
+		return
+	}
+	p.push(key, stale.UnixNano())
+}
+
+func (p *freshness) Hit(string) {}
+
+// NewARC creates an adaptive replacement cache policy for a content store
+// of the given size.
+//
+// It balances between recency and frequency by remembering recently
+// evicted keys.
+//
+// See https://www.usenix.org/conference/fast-03/arc-self-tuning-low-overhead-replacement-cache.
+func NewARC(size int) Policy {
+	p := &arc{
+		size:  size,
+		index: make(map[string]arcEntry),
+	}
+	for i := range p.lists {
+		p.lists[i] = list.New()
+	}
+	return p
+}
+
+// arc lists
+const (
+	arcT1 = iota // seen once recently
+	arcT2        // seen at least twice recently
+	arcB1        // evicted from T1
+	arcB2        // evicted from T2
+)
+
+type arcEntry struct {
+	list int
+	elem *list.Element
+}
+
+type arc struct {
+	size   int
+	target int // target size of T1
+	lists  [4]*list.List
+	index  map[string]arcEntry
+}
+
+func (p *arc) move(key string, to int) {
+	if ent, ok := p.index[key]; ok {
+		p.lists[ent.list].Remove(ent.elem)
+	}
+	p.index[key] = arcEntry{
+		list: to,
+		elem: p.lists[to].PushFront(key),
+	}
+}
+
+func (p *arc) drop(l int) {
+	elem := p.lists[l].Back()
+	if elem == nil {
+		return
+	}
+	delete(p.index, p.lists[l].Remove(elem).(string))
+}
+
+func (p *arc) Add(key string, _ *Data) {
+	ent, ok := p.index[key]
+	if !ok {
+		// complete miss; bound history
+		if p.lists[arcT1].Len()+p.lists[arcB1].Len() >= p.size {
+			p.drop(arcB1)
+		}
+		if p.lists[arcB1].Len()+p.lists[arcB2].Len() >= p.size {
+			p.drop(arcB2)
+		}
+		p.move(key, arcT1)
+		return
+	}
+	switch ent.list {
+	case arcB1:
+		// recency is undervalued
+		delta := 1
+		if b1, b2 := p.lists[arcB1].Len(), p.lists[arcB2].Len(); b2 > b1 {
+			delta = b2 / b1
+		}
+		p.target += delta
+		if p.target > p.size {
+			p.target = p.size
+		}
+	case arcB2:
+		// frequency is undervalued
+		delta := 1
+		if b1, b2 := p.lists[arcB1].Len(), p.lists[arcB2].Len(); b1 > b2 {
+			delta = b1 / b2
+		}
+		p.target -= delta
+		if p.target < 0 {
+			p.target = 0
+		}
+	}
+	p.move(key, arcT2)
+}
+
+func (p *arc) Hit(key string) {
+	if ent, ok := p.index[key]; ok && (ent.list == arcT1 || ent.list == arcT2) {
+		p.move(key, arcT2)
+	}
+}
+
+// Remove forgets a cached key, but keeps history.
+func (p *arc) Remove(key string) {
+	if ent, ok := p.index[key]; ok && (ent.list == arcT1 || ent.list == arcT2) {
+		p.lists[ent.list].Remove(ent.elem)
+		delete(p.index, key)
+	}
+}
+
+func (p *arc) Evict() string {
+	from, to := arcT2, arcB2
+	if t1 := p.lists[arcT1].Len(); t1 > 0 && (t1 > p.target || p.lists[arcT2].Len() == 0) {
+		from, to = arcT1, arcB1
+	}
+	elem := p.lists[from].Back()
+	if elem == nil {
+		return ""
+	}
+	key := elem.Value.(string)
+	p.move(key, to)
+	return key
+}
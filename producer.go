@@ -0,0 +1,177 @@
+package ndn
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+// DefaultSegmentSize is the content size of each segment if the size given
+// to NewProducer is not positive.
+const DefaultSegmentSize = 4096
+
+// Producer publishes segmented objects and answers interests from its cache.
+//
+// Published objects can be retrieved by Fetcher.
+type Producer struct {
+	cache       Cache
+	key         Key
+	segmentSize int
+
+	// FreshnessPeriod of each segment in milliseconds.
+	FreshnessPeriod uint64
+
+	latest  map[string]Name // prefix -> versioned name
+	version uint64          // last published version
+	mu      sync.Mutex
+}
+
+// NewProducer creates a producer that adds published segments to c.
+//
+// If key is nil, segments are signed with DigestSHA256.
+// If segmentSize is not positive, DefaultSegmentSize is used.
+func NewProducer(c Cache, key Key, segmentSize int) *Producer {
+	if segmentSize <= 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	return &Producer{
+		cache:       c,
+		key:         key,
+		segmentSize: segmentSize,
+		latest:      make(map[string]Name),
+	}
+}
+
+// Publish reads r until EOF, and publishes the content as a new version
+// under prefix.
+//
+// Every segment is named /<prefix>/<version>/<segment>, and the final segment
+// has FinalBlockID. The versioned name is returned.
+//
+// The version is the publishing time in milliseconds like certificates,
+// but it always increases even if the clock does not.
+// Segments are added to the cache only after r is fully read,
+// so nothing is published if reading fails.
+func (p *Producer) Publish(prefix Name, r io.Reader) (Name, error) {
+	versioned := prefix.AppendVersion(p.nextVersion())
+
+	var segments []*Data
+	buf := make([]byte, p.segmentSize)
+	n, err := io.ReadFull(r, buf)
+	for seg := uint64(0); ; seg++ {
+		var final bool
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			final = true
+		default:
+			return Name{}, err
+		}
+		content := make([]byte, n)
+		copy(content, buf)
+		if !final {
+			// read ahead to find the final segment
+			n, err = io.ReadFull(r, buf)
+			if err == io.EOF {
+				final = true
+			}
+		}
+
+		d := &Data{
+			Name: versioned.AppendSegment(seg),
+			MetaInfo: MetaInfo{
+				FreshnessPeriod: p.FreshnessPeriod,
+			},
+			Content: content,
+		}
+		if final {
+			d.MetaInfo.FinalBlockID = NewFinalBlockID(d.Name)
+		}
+		if err := p.sign(d); err != nil {
+			return Name{}, err
+		}
+		segments = append(segments, d)
+		if final {
+			break
+		}
+	}
+	for _, d := range segments {
+		p.cache.Add(d)
+	}
+
+	p.mu.Lock()
+	p.latest[prefix.String()] = versioned
+	p.mu.Unlock()
+	return versioned, nil
+}
+
+func (p *Producer) nextVersion() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v := timeVersion(time.Now())
+	if v <= p.version {
+		v = p.version + 1
+	}
+	p.version = v
+	return v
+}
+
+func (p *Producer) sign(d *Data) error {
+	if p.key == nil {
+		d.SignatureInfo.SignatureType = SignatureTypeDigestSHA256
+		return nil
+	}
+	return SignData(p.key, d)
+}
+
+// Serve answers interests from recv until it is closed.
+//
+// RDR metadata interests are answered with the latest version.
+// Interests that cannot be satisfied are ignored.
+func (p *Producer) Serve(w Sender, recv <-chan *Interest) {
+	for i := range recv {
+		d := p.cache.Get(i)
+		if d == nil {
+			d = p.metadata(i)
+		}
+		if d == nil {
+			continue
+		}
+		w.SendData(d)
+	}
+}
+
+// metadata creates a RDR metadata packet if i is a metadata interest.
+func (p *Producer) metadata(i *Interest) *Data {
+	keyword, ok := i.Name.Keyword()
+	if !ok || !bytes.Equal(keyword, metadataKeyword) {
+		return nil
+	}
+	p.mu.Lock()
+	versioned, ok := p.latest[i.Name.Prefix(i.Name.Len()-1).String()]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	err := versioned.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		return nil
+	}
+	d := &Data{
+		Name: i.Name.AppendVersion(timeVersion(time.Now())).AppendSegment(0),
+		MetaInfo: MetaInfo{
+			FreshnessPeriod: 10, // must be refreshed soon
+		},
+		Content: buf.Bytes(),
+	}
+	d.MetaInfo.FinalBlockID = NewFinalBlockID(d.Name)
+	err = p.sign(d)
+	if err != nil {
+		return nil
+	}
+	return d
+}
@@ -0,0 +1,101 @@
+package ndn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestProducerFetcher(t *testing.T) {
+	c1, c2 := net.Pipe()
+	consumer := NewFace(c1, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewFace(c2, recv)
+	defer producer.Close()
+
+	p := NewProducer(NewCache(1024), ecdsaKey, 100)
+	go p.Serve(producer, recv)
+
+	for _, size := range []int{0, 100, 12345} {
+		want := make([]byte, size)
+		rand.Read(want)
+		versioned, err := p.Publish(NewName("/A"), bytes.NewReader(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []Name{NewName("/A"), versioned} {
+			f := &Fetcher{
+				Sender:     consumer,
+				MaxRetries: 1,
+			}
+			r := f.Fetch(context.Background(), name)
+			got, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(want, got) {
+				t.Fatalf("%v: expect %d bytes, got %d bytes", name, len(want), len(got))
+			}
+		}
+	}
+}
+
+func TestProducerPublish(t *testing.T) {
+	c := NewCache(1024)
+	p := NewProducer(c, nil, 100)
+
+	last := timeVersion(time.Now()) - 1
+	for i := 0; i < 10; i++ {
+		versioned, err := p.Publish(NewName("/A"), bytes.NewReader(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := versioned.Version()
+		if v <= last {
+			t.Fatalf("expect version after %d, got %d", last, v)
+		}
+		last = v
+	}
+	// versions are in milliseconds like certificates
+	if now := timeVersion(time.Now()); last > now+10 {
+		t.Fatalf("expect version at most %d, got %d", now+10, last)
+	}
+
+	// nothing is published if reading fails after some segments
+	want := errors.New("read error")
+	_, err := p.Publish(NewName("/B"), io.MultiReader(
+		bytes.NewReader(make([]byte, 250)),
+		iotest.ErrReader(want),
+	))
+	if err != want {
+		t.Fatalf("expect %v, got %v", want, err)
+	}
+	if d := c.Get(&Interest{Name: NewName("/B"), CanBePrefix: true}); d != nil {
+		t.Fatalf("unexpected data %v", d.Name)
+	}
+}
+
+func TestProducerSegmentSize(t *testing.T) {
+	c := NewCache(1024)
+	p := NewProducer(c, nil, 0)
+	versioned, err := p.Publish(NewName("/A"), bytes.NewReader(make([]byte, DefaultSegmentSize+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seg, want := range []int{DefaultSegmentSize, 1} {
+		d := c.Get(&Interest{Name: versioned.AppendSegment(uint64(seg))})
+		if d == nil || len(d.Content) != want {
+			t.Fatalf("expect segment %d of %d bytes, got %v", seg, want, d)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package ndn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Backoff of redialing after the transport of ReconnectingFace fails.
+const (
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// controlRecorder is implemented by faces that replay commands after
+// reconnection.
+type controlRecorder interface {
+	recordControl(module, command string, params *Parameters, key Key)
+}
+
+// NewReconnectingFace creates a face that redials with exponential backoff
+// when its transport fails.
+//
+// After reconnection, routes registered with rib/register by SendControl or
+// Controller are registered again on the new transport, without FaceID.
+// Interests that fail because of the transport are sent again with a new nonce
+// on the new transport within their lifetime.
+// Done is closed and recv is closed only after Close is called.
+//
+// The first transport is dialed before returning.
+// See NewFace.
+func NewReconnectingFace(dial func() (net.Conn, error), recv chan<- *Interest) (Face, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	in := make(chan *Interest)
+	f := &reconnectingFace{
+		dial:        dial,
+		recv:        recv,
+		cur:         NewFace(conn, in),
+		reconnected: make(chan struct{}),
+		routes:      make(map[string]controlRecord),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go f.run(in)
+	return f, nil
+}
+
+type reconnectingFace struct {
+	dial func() (net.Conn, error)
+	recv chan<- *Interest
+
+	cur         Face
+	reconnected chan struct{} // closed when cur is replaced
+	routes      map[string]controlRecord
+	sync.Mutex
+
+	closeOnce sync.Once
+	closing   chan struct{} // closed when Close is called
+	done      chan struct{} // closed when recv is closed
+}
+
+type controlRecord struct {
+	params Parameters
+	key    Key
+}
+
+// run forwards incoming interests, and redials after the transport fails.
+func (f *reconnectingFace) run(in <-chan *Interest) {
+	defer close(f.done)
+	if f.recv != nil {
+		defer close(f.recv)
+	}
+	backoff := minReconnectBackoff
+	for {
+		for i := range in {
+			if f.recv != nil {
+				f.recv <- i
+			}
+		}
+		for {
+			select {
+			case <-f.closing:
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			conn, err := f.dial()
+			if err != nil {
+				continue
+			}
+			in2 := make(chan *Interest)
+			face := NewFace(conn, in2)
+			f.Lock()
+			f.cur = face
+			close(f.reconnected)
+			f.reconnected = make(chan struct{})
+			routes := make([]controlRecord, 0, len(f.routes))
+			for _, rec := range f.routes {
+				routes = append(routes, rec)
+			}
+			f.Unlock()
+			select {
+			case <-f.closing:
+				// Close might have missed the new face
+				face.Close()
+			default:
+			}
+			in = in2
+			go replayRoutes(face, routes)
+			break
+		}
+		backoff = minReconnectBackoff
+	}
+}
+
+// replayRoutes registers routes on a new transport.
+//
+// Failures are ignored; if the transport fails again, routes are replayed
+// after the next reconnection.
+func replayRoutes(w Sender, routes []controlRecord) {
+	for _, rec := range routes {
+		params := rec.params
+		sendControl(w, "rib", "register", &params, rec.key)
+	}
+}
+
+func (f *reconnectingFace) recordControl(module, command string, params *Parameters, key Key) {
+	if module != "rib" {
+		return
+	}
+	routeKey := fmt.Sprintf("%s/%d", params.Name, params.Origin)
+	f.Lock()
+	defer f.Unlock()
+	switch command {
+	case "register":
+		rec := controlRecord{
+			params: *params,
+			key:    key,
+		}
+		// the face id of the old transport is not valid any more
+		rec.params.FaceID = 0
+		f.routes[routeKey] = rec
+	case "unregister":
+		delete(f.routes, routeKey)
+	}
+}
+
+// current returns the current face, and a channel that is closed
+// when it is replaced.
+func (f *reconnectingFace) current() (Face, <-chan struct{}) {
+	f.Lock()
+	defer f.Unlock()
+	return f.cur, f.reconnected
+}
+
+func (f *reconnectingFace) SendInterest(i *Interest) (*Data, error) {
+	return f.SendInterestContext(context.Background(), i)
+}
+
+func (f *reconnectingFace) SendInterestContext(ctx context.Context, i *Interest) (*Data, error) {
+	lifeTime := 4 * time.Second
+	if i.LifeTime != 0 {
+		lifeTime = time.Duration(i.LifeTime) * time.Millisecond
+	}
+	deadline := time.Now().Add(lifeTime)
+	for {
+		face, reconnected := f.current()
+		d, err := face.SendInterestContext(ctx, i)
+		if err == nil {
+			return d, nil
+		}
+		select {
+		case <-face.Done():
+		default:
+			return nil, err
+		}
+		if err != face.Err() {
+			return nil, err
+		}
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-reconnected:
+			timer.Stop()
+			// the interest might have been forwarded before the failure
+			i.Nonce = nil
+		case <-timer.C:
+			return nil, ErrTimeout
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-f.closing:
+			timer.Stop()
+			return nil, ErrFaceClosed
+		}
+	}
+}
+
+func (f *reconnectingFace) SendData(d *Data) error {
+	face, _ := f.current()
+	return face.SendData(d)
+}
+
+func (f *reconnectingFace) SendNack(i *Interest, reason uint64) error {
+	face, _ := f.current()
+	return face.SendNack(i, reason)
+}
+
+func (f *reconnectingFace) LocalAddr() net.Addr {
+	face, _ := f.current()
+	return face.LocalAddr()
+}
+
+func (f *reconnectingFace) RemoteAddr() net.Addr {
+	face, _ := f.current()
+	return face.RemoteAddr()
+}
+
+// Close closes the current transport, and stops reconnection.
+func (f *reconnectingFace) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.closing)
+	})
+	face, _ := f.current()
+	return face.Close()
+}
+
+// Done is closed after Close is called, and recv is closed.
+func (f *reconnectingFace) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err returns ErrFaceClosed after Close is called, or the error of
+// the current transport until it is reconnected.
+func (f *reconnectingFace) Err() error {
+	select {
+	case <-f.done:
+		return ErrFaceClosed
+	default:
+	}
+	face, _ := f.current()
+	select {
+	case <-face.Done():
+		return face.Err()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+package ndn
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+func TestReconnectingFace(t *testing.T) {
+	l, err := ListenFace("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	registered := make(chan Parameters, 16)
+	nonces := make(chan []byte, 16)
+	go func() {
+		for {
+			f, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				for i := range f.Recv {
+					if i.Name.String() == "/A/3" {
+						// the first attempt is lost with the connection
+						nonces <- i.Nonce
+						if len(nonces) == 1 {
+							f.Close()
+							continue
+						}
+					}
+					if i.Name.Len() < 5 || string(i.Name.Components[0]) != "localhost" {
+						f.SendData(&Data{
+							Name: i.Name,
+						})
+						continue
+					}
+					var params Parameters
+					err := tlv.Unmarshal(i.Name.Components[4], &params, 104)
+					if err != nil {
+						continue
+					}
+					registered <- params
+					b, err := tlv.Marshal(&CommandResponse{
+						StatusCode: 200,
+						StatusText: "OK",
+						Parameters: params,
+					}, 101)
+					if err != nil {
+						continue
+					}
+					f.SendData(&Data{
+						Name:    i.Name,
+						Content: b,
+					})
+				}
+			}()
+		}
+	}()
+
+	f, err := NewReconnectingFace(func() (net.Conn, error) {
+		return net.Dial("tcp", l.Addr().String())
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = SendControl(f, "rib", "register", &Parameters{
+		Name:   NewName("/A"),
+		FaceID: 7,
+	}, ed25519Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := <-registered; got.Name.String() != "/A" {
+		t.Fatalf("expect /A to be registered, got %s", got.Name)
+	}
+
+	// the forwarder drops the connection
+	l.Faces()[0].Close()
+	for deadline := time.Now().Add(time.Second); f.Err() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("expect transport error during reconnection")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case got := <-registered:
+		if got.Name.String() != "/A" || got.FaceID != 0 {
+			t.Fatalf("expect /A to be registered again without face id, got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect route to be registered again")
+	}
+	if err := f.Err(); err != nil {
+		t.Fatalf("expect no error after reconnection, got %v", err)
+	}
+	_, err = f.SendInterest(&Interest{
+		Name: NewName("/A/1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// interest is sent again after reconnection
+	l.Faces()[0].Close()
+	_, err = f.SendInterest(&Interest{
+		Name: NewName("/A/2"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// interest is sent again with a new nonce
+	_, err = f.SendInterest(&Interest{
+		Name: NewName("/A/3"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, second := <-nonces, <-nonces; bytes.Equal(first, second) {
+		t.Fatalf("expect a new nonce, got %v twice", first)
+	}
+
+	f.Close()
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expect face to be closed")
+	}
+	if f.Err() != ErrFaceClosed {
+		t.Fatalf("expect %v, got %v", ErrFaceClosed, f.Err())
+	}
+}
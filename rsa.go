@@ -6,8 +6,6 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
-
-	"github.com/go-ndn/tlv"
 )
 
 // RSAKey implements Key.
@@ -38,7 +36,7 @@ func (key *RSAKey) SignatureType() uint64 {
 
 // Sign creates signature.
 func (key *RSAKey) Sign(v interface{}) ([]byte, error) {
-	digest, err := tlv.Hash(sha256.New, v)
+	digest, err := hashSigned(sha256.New, v)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +45,7 @@ func (key *RSAKey) Sign(v interface{}) ([]byte, error) {
 
 // Verify checks signature.
 func (key *RSAKey) Verify(v interface{}, signature []byte) error {
-	digest, err := tlv.Hash(sha256.New, v)
+	digest, err := hashSigned(sha256.New, v)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,114 @@
+package ndn
+
+import (
+	"github.com/go-ndn/lpm"
+)
+
+type ruleMatcher struct{ ruleNode }
+type ruleNode struct {
+	val   *Checker
+	table map[string]*ruleNode
+}
+
+func (n *ruleNode) Empty() bool {
+	return n.val == nil && len(n.table) == 0
+}
+func ruleDeref(val *Checker) (Checker, bool) {
+	if val == nil {
+		var t Checker
+		return t, false
+	}
+	return *val, true
+}
+func (n *ruleNode) Match(key []lpm.Component) (val Checker, found bool) {
+	if len(key) == 0 {
+		return ruleDeref(n.val)
+	}
+	if n.table == nil {
+		return ruleDeref(n.val)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return ruleDeref(n.val)
+	}
+	return child.Match(key[1:])
+}
+func (n *ruleNode) Get(key []lpm.Component) (val Checker, found bool) {
+	if len(key) == 0 {
+		return ruleDeref(n.val)
+	}
+	if n.table == nil {
+		return ruleDeref(nil)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return ruleDeref(nil)
+	}
+	return child.Get(key[1:])
+}
+func (n *ruleNode) Update(key []lpm.Component, val Checker) {
+	if len(key) == 0 {
+		n.val = &val
+		return
+	}
+	if n.table == nil {
+		n.table = make(map[string]*ruleNode)
+	}
+	if _, ok := n.table[string(key[0])]; !ok {
+		n.table[string(key[0])] = &ruleNode{}
+	}
+	n.table[string(key[0])].Update(key[1:], val)
+}
+func (n *ruleNode) Delete(key []lpm.Component) {
+	if len(key) == 0 {
+		n.val = nil
+		return
+	}
+	if n.table == nil {
+		return
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return
+	}
+	child.Delete(key[1:])
+	if child.Empty() {
+		delete(n.table, string(key[0]))
+	}
+}
+
+type ruleUpdateFunc func([]lpm.Component, Checker) (val Checker, del bool)
+
+func (n *ruleNode) UpdateAll(key []lpm.Component, f ruleUpdateFunc) {
+	for i := len(key); i > 0; i-- {
+		k := key[:i]
+		val, _ := n.Get(k)
+		val2, del := f(k, val)
+		if !del {
+			n.Update(k, val2)
+		} else {
+			n.Delete(k)
+		}
+	}
+}
+func (n *ruleNode) visit(key []lpm.Component, f func([]lpm.Component)) {
+	for k, v := range n.table {
+		v.visit(append(key, lpm.Component(k)), f)
+	}
+	if n.val != nil {
+		f(key)
+	}
+}
+func (n *ruleNode) Visit(f ruleUpdateFunc) {
+	n.visit(make([]lpm.Component, 0, 16), func(k []lpm.Component) {
+		val, found := n.Get(k)
+		if found {
+			val2, del := f(k, val)
+			if !del {
+				n.Update(k, val2)
+			} else {
+				n.Delete(k)
+			}
+		}
+	})
+}
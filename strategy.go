@@ -0,0 +1,40 @@
+package ndn
+
+// ForwardingStrategy decides where a forwarder sends interests.
+//
+// ForwardingStrategy is not required to be thread-safe.
+type ForwardingStrategy interface {
+	// Forward chooses upstream faces for an interest received on face in.
+	// Next hops are sorted by cost, and never include face in.
+	Forward(i *Interest, in uint64, nextHops []NextHopRecord) []uint64
+}
+
+// NewBestRoute creates a strategy that forwards interests to the next hop
+// with the lowest cost.
+func NewBestRoute() ForwardingStrategy {
+	return bestRoute{}
+}
+
+type bestRoute struct{}
+
+func (bestRoute) Forward(_ *Interest, _ uint64, nextHops []NextHopRecord) []uint64 {
+	if len(nextHops) == 0 {
+		return nil
+	}
+	return []uint64{nextHops[0].FaceID}
+}
+
+// NewMulticast creates a strategy that forwards interests to all next hops.
+func NewMulticast() ForwardingStrategy {
+	return multicast{}
+}
+
+type multicast struct{}
+
+func (multicast) Forward(_ *Interest, _ uint64, nextHops []NextHopRecord) []uint64 {
+	faceIDs := make([]uint64, len(nextHops))
+	for i, nh := range nextHops {
+		faceIDs[i] = nh.FaceID
+	}
+	return faceIDs
+}
@@ -0,0 +1,114 @@
+package ndn
+
+import (
+	"github.com/go-ndn/lpm"
+)
+
+type strategyMatcher struct{ strategyNode }
+type strategyNode struct {
+	val   *ForwardingStrategy
+	table map[string]*strategyNode
+}
+
+func (n *strategyNode) Empty() bool {
+	return n.val == nil && len(n.table) == 0
+}
+func strategyDeref(val *ForwardingStrategy) (ForwardingStrategy, bool) {
+	if val == nil {
+		var t ForwardingStrategy
+		return t, false
+	}
+	return *val, true
+}
+func (n *strategyNode) Match(key []lpm.Component) (val ForwardingStrategy, found bool) {
+	if len(key) == 0 {
+		return strategyDeref(n.val)
+	}
+	if n.table == nil {
+		return strategyDeref(n.val)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return strategyDeref(n.val)
+	}
+	return child.Match(key[1:])
+}
+func (n *strategyNode) Get(key []lpm.Component) (val ForwardingStrategy, found bool) {
+	if len(key) == 0 {
+		return strategyDeref(n.val)
+	}
+	if n.table == nil {
+		return strategyDeref(nil)
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return strategyDeref(nil)
+	}
+	return child.Get(key[1:])
+}
+func (n *strategyNode) Update(key []lpm.Component, val ForwardingStrategy) {
+	if len(key) == 0 {
+		n.val = &val
+		return
+	}
+	if n.table == nil {
+		n.table = make(map[string]*strategyNode)
+	}
+	if _, ok := n.table[string(key[0])]; !ok {
+		n.table[string(key[0])] = &strategyNode{}
+	}
+	n.table[string(key[0])].Update(key[1:], val)
+}
+func (n *strategyNode) Delete(key []lpm.Component) {
+	if len(key) == 0 {
+		n.val = nil
+		return
+	}
+	if n.table == nil {
+		return
+	}
+	child, ok := n.table[string(key[0])]
+	if !ok {
+		return
+	}
+	child.Delete(key[1:])
+	if child.Empty() {
+		delete(n.table, string(key[0]))
+	}
+}
+
+type strategyUpdateFunc func([]lpm.Component, ForwardingStrategy) (val ForwardingStrategy, del bool)
+
+func (n *strategyNode) UpdateAll(key []lpm.Component, f strategyUpdateFunc) {
+	for i := len(key); i > 0; i-- {
+		k := key[:i]
+		val, _ := n.Get(k)
+		val2, del := f(k, val)
+		if !del {
+			n.Update(k, val2)
+		} else {
+			n.Delete(k)
+		}
+	}
+}
+func (n *strategyNode) visit(key []lpm.Component, f func([]lpm.Component)) {
+	for k, v := range n.table {
+		v.visit(append(key, lpm.Component(k)), f)
+	}
+	if n.val != nil {
+		f(key)
+	}
+}
+func (n *strategyNode) Visit(f strategyUpdateFunc) {
+	n.visit(make([]lpm.Component, 0, 16), func(k []lpm.Component) {
+		val, found := n.Get(k)
+		if found {
+			val2, del := f(k, val)
+			if !del {
+				n.Update(k, val2)
+			} else {
+				n.Delete(k)
+			}
+		}
+	})
+}
@@ -1,11 +1,90 @@
 package ndn
 
-import "github.com/go-ndn/tlv"
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/go-ndn/tlv"
+)
+
+// ErrPacketTooLarge is returned when a received packet exceeds maxPacketSize.
+var ErrPacketTooLarge = errors.New("packet too large")
+
+// maxPacketSize is the maximum size of a packet received by a face,
+// which is also the maximum size of a datagram.
+const maxPacketSize = 65535
 
 func init() {
 	// zero-allocation tlv
-	tlv.CacheType((*Interest)(nil))
+	// Interest is not listed, because it is always encoded by WriteTo and ReadFrom.
 	tlv.CacheType((*Data)(nil))
 	tlv.CacheType((*Command)(nil))
 	tlv.CacheType((*CommandResponse)(nil))
 }
+
+// readRecord reads the next tlv element, and returns its type and encoding.
+//
+// io.EOF is returned only if there is no more element, and io.ErrUnexpectedEOF
+// is returned if the element is truncated. Other errors of r are returned as is.
+// ErrPacketTooLarge is returned before reading the value if the element
+// is larger than limit.
+func readRecord(r *bufio.Reader, limit int64) (uint64, []byte, error) {
+	buf := new(bytes.Buffer)
+	t, err := readVarNum(r, buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	l, err := readVarNum(r, buf)
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	if l > uint64(limit) || int64(l) > limit-int64(buf.Len()) {
+		return 0, nil, ErrPacketTooLarge
+	}
+	_, err = io.CopyN(buf, r, int64(l))
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	return t, buf.Bytes(), nil
+}
+
+// unexpectedEOF turns io.EOF in the middle of an element into io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readVarNum reads a variable-length number, and copies its encoding to buf.
+func readVarNum(r *bufio.Reader, buf *bytes.Buffer) (uint64, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	buf.WriteByte(b)
+	var n int
+	switch b {
+	case 0xfd:
+		n = 2
+	case 0xfe:
+		n = 4
+	case 0xff:
+		n = 8
+	default:
+		return uint64(b), nil
+	}
+	ext := make([]byte, n)
+	_, err = io.ReadFull(r, ext)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	buf.Write(ext)
+	var v uint64
+	for _, b := range ext {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
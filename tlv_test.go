@@ -0,0 +1,30 @@
+package ndn
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadRecord(t *testing.T) {
+	readErr := errors.New("read error")
+	for _, test := range []struct {
+		in   io.Reader
+		want error
+	}{
+		{in: bytes.NewReader([]byte{6, 2, 1, 2})},
+		{in: bytes.NewReader(nil), want: io.EOF},
+		{in: bytes.NewReader([]byte{6, 0xfe, 0x7f, 0xff, 0xff, 0xff}), want: ErrPacketTooLarge},
+		{in: bytes.NewReader([]byte{6, 2, 1}), want: io.ErrUnexpectedEOF},
+		{in: bytes.NewReader([]byte{6, 0xfd, 1}), want: io.ErrUnexpectedEOF},
+		{in: io.MultiReader(bytes.NewReader([]byte{6, 2, 1}), iotest.ErrReader(readErr)), want: readErr},
+	} {
+		_, _, err := readRecord(bufio.NewReader(test.in), 4)
+		if err != test.want {
+			t.Fatalf("expect %v, got %v", test.want, err)
+		}
+	}
+}
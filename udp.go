@@ -0,0 +1,325 @@
+package ndn
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+// DefaultUDPMTU is the maximum datagram size of UDP faces.
+//
+// Larger packets are fragmented with NDNLPv2.
+const DefaultUDPMTU = 1400
+
+const (
+	// reassemblyTimeout is how long an incomplete packet is kept.
+	reassemblyTimeout = 500 * time.Millisecond
+	// maxDatagramSize is the size of the receive buffer.
+	maxDatagramSize = maxPacketSize
+	// maxFragCount is the maximum number of fragments of a packet.
+	maxFragCount = 256
+	// maxReassembly is the maximum number of incomplete packets
+	// from each remote address.
+	maxReassembly = 16
+)
+
+// DialUDP creates a unicast UDP face to address, such as "10.0.0.1:6363".
+//
+// See NewFace.
+func DialUDP(address string, recv chan<- *Interest) (Face, error) {
+	raddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+	return NewDatagramFace(conn, DefaultUDPMTU, recv), nil
+}
+
+// ListenMulticastUDP creates a UDP face that joins the multicast group
+// on ifi, such as "224.0.23.170:56363".
+//
+// If ifi is nil, the system-assigned interface is used.
+// See NewFace.
+func ListenMulticastUDP(ifi *net.Interface, group string, recv chan<- *Interest) (Face, error) {
+	gaddr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp", ifi, gaddr)
+	if err != nil {
+		return nil, err
+	}
+	return NewFace(newDatagramConn(conn, gaddr, DefaultUDPMTU), recv), nil
+}
+
+// NewDatagramFace creates a face from a datagram transport like
+// connected *net.UDPConn.
+//
+// Each datagram contains one packet. Packets larger than mtu are fragmented,
+// and fragments are reassembled with NDNLPv2.
+// A packet has at most 256 fragments. Malformed datagrams are dropped.
+func NewDatagramFace(transport net.Conn, mtu int, recv chan<- *Interest) Face {
+	return NewFace(newDatagramConn(transport, nil, mtu), recv)
+}
+
+// datagramConn turns a datagram transport into a stream of packets.
+type datagramConn struct {
+	net.Conn
+	group *net.UDPAddr // remote multicast group, or nil if transport is connected
+	mtu   int
+
+	rbuf    []byte                            // one datagram
+	pending []byte                            // reassembled packet not read yet
+	partial map[string]map[uint64]*reassembly // remote address -> first sequence
+
+	seq uint64 // next sequence number of fragments
+	wm  sync.Mutex
+}
+
+type reassembly struct {
+	first     *LpPacket
+	fragments [][]byte
+	received  uint64
+	created   time.Time
+}
+
+func newDatagramConn(transport net.Conn, group *net.UDPAddr, mtu int) *datagramConn {
+	return &datagramConn{
+		Conn:    transport,
+		group:   group,
+		mtu:     mtu,
+		rbuf:    make([]byte, maxDatagramSize),
+		partial: make(map[string]map[uint64]*reassembly),
+	}
+}
+
+func (c *datagramConn) RemoteAddr() net.Addr {
+	if c.group != nil {
+		return c.group
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *datagramConn) Read(b []byte) (int, error) {
+	for len(c.pending) == 0 {
+		var (
+			n    int
+			addr net.Addr
+			err  error
+		)
+		if c.group != nil {
+			n, addr, err = c.Conn.(net.PacketConn).ReadFrom(c.rbuf)
+		} else {
+			n, err = c.Conn.Read(c.rbuf)
+			addr = c.Conn.RemoteAddr()
+		}
+		if isConnRefused(err) {
+			// ICMP port unreachable of a previous datagram
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		c.pending = c.recvDatagram(c.rbuf[:n], addr)
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// isConnRefused checks whether err is ECONNREFUSED, which is reported on
+// a connected UDP socket when the remote end is not listening yet.
+func isConnRefused(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+	}
+	if sysErr, ok := err.(*os.SyscallError); ok {
+		err = sysErr.Err
+	}
+	return err == syscall.ECONNREFUSED
+}
+
+// recvDatagram returns the packet in the datagram, or nil if the datagram
+// is malformed or an incomplete fragment.
+func (c *datagramConn) recvDatagram(b []byte, addr net.Addr) []byte {
+	t, pkt, err := readRecord(bufio.NewReader(bytes.NewReader(b)), int64(len(b)))
+	if err != nil || len(pkt) != len(b) {
+		return nil
+	}
+	if t != 100 {
+		return append([]byte(nil), b...)
+	}
+	p := new(LpPacket)
+	err = tlv.Unmarshal(b, p, 100)
+	if err != nil {
+		return nil
+	}
+	if p.FragCount <= 1 {
+		return append([]byte(nil), b...)
+	}
+	return c.reassemble(p, addr)
+}
+
+// reassemble collects the fragment, and returns the packet when all
+// fragments are received.
+func (c *datagramConn) reassemble(p *LpPacket, addr net.Addr) []byte {
+	if p.FragCount > maxFragCount || p.FragIndex >= p.FragCount || p.Sequence < p.FragIndex {
+		return nil
+	}
+	now := time.Now()
+	for from, partial := range c.partial {
+		for seq, r := range partial {
+			if now.Sub(r.created) > reassemblyTimeout {
+				delete(partial, seq)
+			}
+		}
+		if len(partial) == 0 {
+			delete(c.partial, from)
+		}
+	}
+
+	from := addr.String()
+	partial, ok := c.partial[from]
+	if !ok {
+		partial = make(map[uint64]*reassembly)
+		c.partial[from] = partial
+	}
+	seq := p.Sequence - p.FragIndex
+	r, ok := partial[seq]
+	if !ok {
+		if len(partial) >= maxReassembly {
+			return nil
+		}
+		r = &reassembly{
+			fragments: make([][]byte, p.FragCount),
+			created:   now,
+		}
+		partial[seq] = r
+	}
+	if uint64(len(r.fragments)) != p.FragCount || r.fragments[p.FragIndex] != nil {
+		return nil
+	}
+	r.fragments[p.FragIndex] = p.Fragment
+	r.received++
+	if p.FragIndex == 0 {
+		r.first = p
+	}
+	if r.received < p.FragCount {
+		return nil
+	}
+	delete(partial, seq)
+	if len(partial) == 0 {
+		delete(c.partial, from)
+	}
+
+	whole := &LpPacket{
+		Nack:           r.first.Nack,
+		CongestionMark: r.first.CongestionMark,
+		Fragment:       bytes.Join(r.fragments, nil),
+	}
+	b, err := tlv.Marshal(whole, 100)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// Write sends one packet, which is fragmented if it is larger than mtu.
+func (c *datagramConn) Write(b []byte) (int, error) {
+	c.wm.Lock()
+	defer c.wm.Unlock()
+	if len(b) <= c.mtu {
+		return len(b), c.send(b)
+	}
+
+	var p LpPacket
+	if t, _, err := readRecord(bufio.NewReader(bytes.NewReader(b)), int64(len(b))); err == nil && t == 100 {
+		err = tlv.Unmarshal(b, &p, 100)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		p.Fragment = b
+	}
+	overhead, err := c.lpOverhead(&p)
+	if err != nil {
+		return 0, err
+	}
+	size := c.mtu - overhead
+	if size <= 0 {
+		return 0, ErrNotSupported
+	}
+	count := uint64((len(p.Fragment) + size - 1) / size)
+	if count > maxFragCount {
+		return 0, ErrNotSupported
+	}
+	base := c.seq
+	c.seq += count
+	for i := uint64(0); i < count; i++ {
+		frag := &LpPacket{
+			Sequence:  base + i,
+			FragIndex: i,
+			FragCount: count,
+		}
+		if i == 0 {
+			frag.Nack = p.Nack
+			frag.CongestionMark = p.CongestionMark
+		}
+		end := int(i+1) * size
+		if end > len(p.Fragment) {
+			end = len(p.Fragment)
+		}
+		frag.Fragment = p.Fragment[int(i)*size : end]
+		fb, err := tlv.Marshal(frag, 100)
+		if err != nil {
+			return 0, err
+		}
+		if len(fb) > c.mtu {
+			return 0, ErrNotSupported
+		}
+		err = c.send(fb)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// lpOverhead returns the maximum size of LpPacket headers of any fragment of p,
+// including the tlv headers of LpPacket and Fragment.
+//
+// It is computed from the first fragment, which carries all headers of p,
+// with FragIndex and FragCount as large as possible and Fragment of mtu.
+func (c *datagramConn) lpOverhead(p *LpPacket) (int, error) {
+	frag := &LpPacket{
+		Sequence:       c.seq,
+		FragIndex:      maxFragCount,
+		FragCount:      maxFragCount,
+		Nack:           p.Nack,
+		CongestionMark: p.CongestionMark,
+		Fragment:       make([]byte, c.mtu),
+	}
+	b, err := tlv.Marshal(frag, 100)
+	if err != nil {
+		return 0, err
+	}
+	return len(b) - c.mtu, nil
+}
+
+func (c *datagramConn) send(b []byte) error {
+	if c.group != nil {
+		_, err := c.Conn.(net.PacketConn).WriteTo(b, c.group)
+		return err
+	}
+	_, err := c.Conn.Write(b)
+	return err
+}
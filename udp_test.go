@@ -0,0 +1,192 @@
+package ndn
+
+import (
+	"bytes"
+	"math/rand"
+	"net"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/go-ndn/tlv"
+)
+
+func testDatagramFace(t *testing.T, consumer, producer Face, recv <-chan *Interest) {
+	want := make([]byte, 5000)
+	rand.Read(want)
+	go func() {
+		for i := range recv {
+			if string(i.Name.Components[0]) == "nack" {
+				producer.SendNack(i, NackReasonCongestion)
+				continue
+			}
+			producer.SendData(&Data{
+				Name:    i.Name,
+				Content: want,
+			})
+		}
+	}()
+
+	d, err := consumer.SendInterest(&Interest{
+		Name: NewName("/A"),
+		// large interest is fragmented too
+		ApplicationParameters: want,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, d.Content) {
+		t.Fatalf("expect %d bytes, got %d bytes", len(want), len(d.Content))
+	}
+
+	_, err = consumer.SendInterest(&Interest{
+		Name:                  NewName("/nack"),
+		ApplicationParameters: want,
+	})
+	if nack, ok := err.(*NackError); !ok || nack.Reason != NackReasonCongestion {
+		t.Fatalf("expect nack Congestion, got %v", err)
+	}
+}
+
+func TestDatagramFace(t *testing.T) {
+	c1, c2 := NewPipe(Link{})
+	consumer := NewDatagramFace(c1, 500, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewDatagramFace(c2, 500, recv)
+	defer producer.Close()
+	testDatagramFace(t, consumer, producer, recv)
+
+	// fragments of a lost packet are dropped
+	c := newDatagramConn(nil, nil, 500)
+	addr := c1.LocalAddr()
+	for _, p := range []*LpPacket{
+		{Sequence: 10, FragIndex: 0, FragCount: 2, Fragment: []byte{1}},
+		{Sequence: 12, FragIndex: 0, FragCount: 2, Fragment: []byte{3}},
+		{Sequence: 13, FragIndex: 1, FragCount: 2, Fragment: []byte{4}},
+	} {
+		b := c.reassemble(p, addr)
+		if p.Sequence == 13 {
+			var whole LpPacket
+			err := whole.UnmarshalBinary(b[2:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(whole.Fragment, []byte{3, 4}) {
+				t.Fatalf("expect reassembled fragment [3 4], got %v", whole.Fragment)
+			}
+		} else if b != nil {
+			t.Fatalf("unexpected packet %v", b)
+		}
+	}
+
+	// too many fragments
+	b := c.reassemble(&LpPacket{Sequence: 20, FragIndex: 0, FragCount: maxFragCount + 1}, addr)
+	if b != nil || len(c.partial[addr.String()]) != 1 {
+		t.Fatalf("expect fragment with FragCount %d to be dropped", maxFragCount+1)
+	}
+
+	// too many incomplete packets
+	for seq := uint64(100); seq < 100+2*maxReassembly; seq += 2 {
+		c.reassemble(&LpPacket{Sequence: seq, FragIndex: 0, FragCount: 2}, addr)
+	}
+	if got := len(c.partial[addr.String()]); got != maxReassembly {
+		t.Fatalf("expect %d incomplete packets, got %d", maxReassembly, got)
+	}
+}
+
+// refusedConn fails the first read with ECONNREFUSED.
+type refusedConn struct {
+	net.Conn
+	refused bool
+}
+
+func (c *refusedConn) Read(b []byte) (int, error) {
+	if !c.refused {
+		c.refused = true
+		return 0, &net.OpError{Op: "read", Net: "udp", Err: os.NewSyscallError("read", syscall.ECONNREFUSED)}
+	}
+	return c.Conn.Read(b)
+}
+
+func TestDatagramFaceConnRefused(t *testing.T) {
+	c1, c2 := NewPipe(Link{})
+	consumer := NewDatagramFace(c1, 500, nil)
+	defer consumer.Close()
+	recv := make(chan *Interest)
+	producer := NewDatagramFace(&refusedConn{Conn: c2}, 500, recv)
+	defer producer.Close()
+	testDatagramFace(t, consumer, producer, recv)
+}
+
+func TestUDPFace(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	consumer, err := DialUDP(laddr.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+	conn.Close()
+
+	conn, err = net.DialUDP("udp", laddr, consumer.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv := make(chan *Interest)
+	producer := NewDatagramFace(conn, DefaultUDPMTU, recv)
+	defer producer.Close()
+	testDatagramFace(t, consumer, producer, recv)
+}
+
+// datagramRecorder records written datagrams.
+type datagramRecorder struct {
+	net.Conn
+	datagrams [][]byte
+}
+
+func (c *datagramRecorder) Write(b []byte) (int, error) {
+	c.datagrams = append(c.datagrams, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func TestDatagramFaceMTU(t *testing.T) {
+	const mtu = 64
+	want := &LpPacket{
+		Nack:           &Nack{Reason: NackReasonNoRoute},
+		CongestionMark: 1 << 40,
+		Fragment:       bytes.Repeat([]byte{5, 0}, 1000),
+	}
+	b, err := tlv.Marshal(want, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &datagramRecorder{}
+	c := newDatagramConn(w, nil, mtu)
+	_, err = c.Write(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newDatagramConn(nil, nil, mtu)
+	addr := linkAddr("link")
+	var whole []byte
+	for _, datagram := range w.datagrams {
+		if len(datagram) > mtu {
+			t.Fatalf("expect at most %d bytes, got %d bytes", mtu, len(datagram))
+		}
+		whole = r.recvDatagram(datagram, addr)
+	}
+	got := new(LpPacket)
+	err = tlv.Unmarshal(whole, got, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("expect %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,177 @@
+package ndn
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-ndn/lpm"
+)
+
+// Errors introduced by Validator.
+var (
+	ErrUntrusted = errors.New("untrusted signature")
+)
+
+// Checker decides whether the key named keyName is allowed to sign
+// the data packet named name.
+type Checker func(name, keyName Name) bool
+
+// Hierarchical is a Checker that allows a key to sign data packets
+// under its identity.
+func Hierarchical(name, keyName Name) bool {
+	identity := keyName.Prefix(keyName.Len() - 2)
+	if name.Len() < identity.Len() {
+		return false
+	}
+	prefix := name.Prefix(identity.Len())
+	return prefix.Compare(identity) == 0
+}
+
+// Validator verifies data packets with a chain of certificates that ends
+// at a trust anchor.
+//
+// Each data packet and certificate in the chain must be allowed by the rule
+// of the longest matching prefix. Missing certificates are retrieved by
+// KeyLocator with Sender, and cached once the chain is verified.
+type Validator struct {
+	// MaxDepth is the maximum number of certificates in a chain,
+	// excluding the trust anchor.
+	MaxDepth int
+
+	sender  Sender
+	rules   ruleMatcher
+	anchors map[string]*Certificate // key name -> certificate
+	certs   Cache
+	mu      sync.RWMutex
+}
+
+// NewValidator creates a validator that retrieves certificates with s,
+// and caches at most size verified certificates.
+func NewValidator(s Sender, size int) *Validator {
+	return &Validator{
+		sender:   s,
+		MaxDepth: 5,
+		anchors:  make(map[string]*Certificate),
+		certs:    NewCache(size),
+	}
+}
+
+// AddAnchor trusts the certificate without verification.
+func (v *Validator) AddAnchor(cert *Certificate) {
+	v.mu.Lock()
+	v.anchors[cert.KeyName().String()] = cert
+	v.mu.Unlock()
+}
+
+// AddRule checks data packets and certificates under prefix with c.
+//
+// An existing rule for the same prefix is replaced.
+func (v *Validator) AddRule(prefix Name, c Checker) {
+	v.mu.Lock()
+	v.rules.Update(prefix.key(), c)
+	v.mu.Unlock()
+}
+
+// Validate verifies d.
+//
+// See ValidateContext.
+func (v *Validator) Validate(d *Data) error {
+	return v.ValidateContext(context.Background(), d)
+}
+
+// ValidateContext verifies d and its certificate chain.
+//
+// ErrUntrusted is returned if the chain is not allowed by rules,
+// does not end at a trust anchor, or any certificate is not valid now.
+func (v *Validator) ValidateContext(ctx context.Context, d *Data) error {
+	var chain []*Certificate
+	for depth := 0; depth <= v.MaxDepth; depth++ {
+		locator := d.SignatureInfo.KeyLocator.Name
+		keyName := locator
+		if isCertificateName(keyName) {
+			keyName = keyName.Prefix(keyName.Len() - 2)
+		}
+		if !isKeyName(keyName) {
+			return ErrUntrusted
+		}
+		v.mu.RLock()
+		checker, ok := v.longestMatch(d.Name.key())
+		anchor, isAnchor := v.anchors[keyName.String()]
+		v.mu.RUnlock()
+		if !ok || !checker(d.Name, keyName) {
+			return ErrUntrusted
+		}
+
+		now := time.Now()
+		if isAnchor {
+			if !anchor.Valid(now) {
+				return ErrUntrusted
+			}
+			return v.verify(anchor.Key, d, chain)
+		}
+		cert, verified, err := v.certificate(ctx, locator)
+		if err != nil {
+			return err
+		}
+		if certKeyName := cert.KeyName(); certKeyName.Compare(keyName) != 0 || !cert.Valid(now) {
+			return ErrUntrusted
+		}
+		if verified {
+			return v.verify(cert.Key, d, chain)
+		}
+		err = VerifyData(cert.Key, d)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, cert)
+		d = cert.Data
+	}
+	return ErrUntrusted
+}
+
+// longestMatch finds the rule of the longest prefix that has one.
+//
+// Validator mutex must be held.
+func (v *Validator) longestMatch(name []lpm.Component) (Checker, bool) {
+	for l := len(name); l >= 0; l-- {
+		if c, ok := v.rules.Get(name[:l]); ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// verify verifies the last packet of the chain with a trusted key,
+// and caches certificates in the chain.
+func (v *Validator) verify(key Key, d *Data, chain []*Certificate) error {
+	err := VerifyData(key, d)
+	if err != nil {
+		return err
+	}
+	for _, cert := range chain {
+		v.certs.Add(cert.Data)
+	}
+	return nil
+}
+
+// certificate finds the certificate named by locator in the cache,
+// or retrieves it.
+func (v *Validator) certificate(ctx context.Context, locator Name) (cert *Certificate, verified bool, err error) {
+	i := &Interest{
+		Name:        locator,
+		CanBePrefix: true,
+	}
+	d := v.certs.Get(i)
+	if d != nil {
+		verified = true
+	} else {
+		d, err = sendInterest(ctx, v.sender, i)
+		if err != nil {
+			return
+		}
+	}
+	cert, err = ParseCertificate(d)
+	return
+}
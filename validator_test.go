@@ -0,0 +1,126 @@
+package ndn
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+// certSender serves certificates from a cache, and counts interests.
+type certSender struct {
+	Cache
+	count int
+}
+
+func (s *certSender) SendInterest(i *Interest) (*Data, error) {
+	s.count++
+	d := s.Get(i)
+	if d == nil {
+		return nil, ErrTimeout
+	}
+	return d, nil
+}
+
+func (s *certSender) SendData(*Data) error {
+	return nil
+}
+
+func newTestKey(t *testing.T, identity string) Key {
+	pub, pri, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := NewKeyName(NewName(identity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Ed25519Key{
+		Name:       name,
+		PrivateKey: pri,
+		PublicKey:  pub,
+	}
+}
+
+func TestValidator(t *testing.T) {
+	now := time.Now()
+	rootKey := newTestKey(t, "/test")
+	aliceKey := newTestKey(t, "/test/alice")
+	expiredKey := newTestKey(t, "/test/expired")
+	unknownKey := newTestKey(t, "/test/unknown")
+
+	root, err := NewCertificate(rootKey, rootKey, nil, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &certSender{Cache: NewCache(10)}
+	for _, test := range []struct {
+		key       Key
+		notBefore time.Time
+		notAfter  time.Time
+	}{
+		{aliceKey, now.Add(-time.Hour), now.Add(time.Hour)},
+		{expiredKey, now.Add(-2 * time.Hour), now.Add(-time.Hour)},
+	} {
+		cert, err := NewCertificate(test.key, rootKey, nil, test.notBefore, test.notAfter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Add(cert.Data)
+	}
+
+	v := NewValidator(s, 10)
+	v.AddAnchor(root)
+	v.AddRule(NewName("/test"), Hierarchical)
+	// deeper rule must not hide the rule of /test
+	v.AddRule(NewName("/test/alice/private/secret"), func(Name, Name) bool {
+		return false
+	})
+
+	for _, test := range []struct {
+		name  string
+		key   Key
+		want  error
+		count int
+	}{
+		{"/test/alice/photo", aliceKey, nil, 1},
+		{"/test/alice/video", aliceKey, nil, 0}, // cached certificate
+		{"/test/photo", rootKey, nil, 0},
+		{"/test/alice/private/photo", aliceKey, nil, 0},
+		{"/test/alice/private/secret/photo", aliceKey, ErrUntrusted, 0},
+		{"/test/bob/photo", aliceKey, ErrUntrusted, 0},
+		{"/other/photo", rootKey, ErrUntrusted, 0},
+		{"/test/expired/photo", expiredKey, ErrUntrusted, 1},
+		{"/test/unknown/photo", unknownKey, ErrTimeout, 1},
+	} {
+		d := &Data{
+			Name: NewName(test.name),
+		}
+		err := SignData(test.key, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.count = 0
+		err = v.Validate(d)
+		if err != test.want {
+			t.Fatalf("Validate(%s) == %v, got %v", test.name, test.want, err)
+		}
+		if s.count != test.count {
+			t.Fatalf("expect %d interests for %s, got %d", test.count, test.name, s.count)
+		}
+	}
+
+	// forged signature
+	d := &Data{
+		Name: NewName("/test/alice/photo"),
+	}
+	err = SignData(unknownKey, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SignatureInfo.KeyLocator.Name = aliceKey.Locator()
+	err = v.Validate(d)
+	if err != ErrInvalidSignature {
+		t.Fatalf("expect %v, got %v", ErrInvalidSignature, err)
+	}
+}
@@ -0,0 +1,138 @@
+package ndn
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors introduced by InterestVerifier.
+var (
+	ErrReplay = errors.New("replayed interest")
+)
+
+// InterestVerifier verifies signed interests, and rejects replayed ones.
+//
+// A signed interest is rejected if its SignatureTime is outside the grace
+// period or earlier than the last one signed by the same key, if its
+// SignatureNonce is seen within the grace period, or if its SignatureSeqNum
+// is not larger than the last one signed by the same key.
+// At least one of them must be present.
+//
+// Keys and nonces are forgotten if they are not seen within the grace period,
+// so SignatureSeqNum alone does not prevent replay after that.
+type InterestVerifier struct {
+	// GracePeriod is the maximum difference between SignatureTime and now.
+	GracePeriod time.Duration
+
+	last   map[string]interestRecord // key name -> last verified interest
+	keys   []keyRecord               // key names in the order seen
+	nonces map[string]time.Time      // nonce -> time seen
+	queue  []nonceRecord             // nonces in the order seen
+	mu     sync.Mutex
+}
+
+type interestRecord struct {
+	time   uint64
+	seqNum uint64
+	seen   time.Time
+}
+
+type keyRecord struct {
+	keyName string
+	seen    time.Time
+}
+
+type nonceRecord struct {
+	nonce string
+	seen  time.Time
+}
+
+// NewInterestVerifier creates a verifier with the grace period of 1 minute.
+func NewInterestVerifier() *InterestVerifier {
+	return &InterestVerifier{
+		GracePeriod: time.Minute,
+		last:        make(map[string]interestRecord),
+		nonces:      make(map[string]time.Time),
+	}
+}
+
+// Verify verifies the signed interest with the given key, and checks replay.
+//
+// The interest is remembered only if it is valid.
+func (v *InterestVerifier) Verify(key Key, i *Interest) error {
+	err := VerifyInterest(key, i)
+	if err != nil {
+		return err
+	}
+	info := i.SignatureInfo
+	if info.SignatureTime == 0 && info.SignatureSeqNum == 0 && len(info.SignatureNonce) == 0 {
+		return ErrReplay
+	}
+
+	now := time.Now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.expire(now)
+
+	keyName := key.Locator().String()
+	last := v.last[keyName]
+	if info.SignatureTime != 0 {
+		t := time.Unix(0, int64(info.SignatureTime)*int64(time.Millisecond))
+		if t.Before(now.Add(-v.GracePeriod)) || t.After(now.Add(v.GracePeriod)) ||
+			info.SignatureTime < last.time {
+			return ErrReplay
+		}
+		last.time = info.SignatureTime
+	}
+	if info.SignatureSeqNum != 0 {
+		if info.SignatureSeqNum <= last.seqNum {
+			return ErrReplay
+		}
+		last.seqNum = info.SignatureSeqNum
+	}
+	if len(info.SignatureNonce) != 0 {
+		nonce := string(info.SignatureNonce)
+		if _, ok := v.nonces[nonce]; ok {
+			return ErrReplay
+		}
+		v.nonces[nonce] = now
+		v.queue = append(v.queue, nonceRecord{
+			nonce: nonce,
+			seen:  now,
+		})
+	}
+	last.seen = now
+	v.last[keyName] = last
+	v.keys = append(v.keys, keyRecord{
+		keyName: keyName,
+		seen:    now,
+	})
+	return nil
+}
+
+// expire forgets nonces and keys that are seen before the grace period.
+func (v *InterestVerifier) expire(now time.Time) {
+	var n int
+	for _, rec := range v.queue {
+		if now.Sub(rec.seen) <= v.GracePeriod {
+			break
+		}
+		delete(v.nonces, rec.nonce)
+		n++
+	}
+	v.queue = v.queue[n:]
+
+	n = 0
+	for _, rec := range v.keys {
+		if now.Sub(rec.seen) <= v.GracePeriod {
+			break
+		}
+		if last, ok := v.last[rec.keyName]; ok && !last.seen.After(rec.seen) {
+			// the key is not seen again since then
+			delete(v.last, rec.keyName)
+		}
+		n++
+	}
+	v.keys = v.keys[n:]
+}
@@ -0,0 +1,131 @@
+package ndn
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-ndn/tlv"
+)
+
+func TestSignedInterest(t *testing.T) {
+	for _, key := range []Key{rsaKey, ecdsaKey, hmacKey, ed25519Key} {
+		i := &Interest{
+			Name:                  NewName("/localhost/nfd/rib/register"),
+			ApplicationParameters: []byte{1, 2, 3},
+		}
+		err := SignInterest(key, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		err = i.WriteTo(tlv.NewWriter(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := buf.Bytes()
+
+		i2 := new(Interest)
+		err = i2.ReadFrom(tlv.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := i2.Name.ParametersSHA256(); !ok {
+			t.Fatal("expect ParametersSha256DigestComponent")
+		}
+		err = VerifyInterest(key, i2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// parameters digest covers signature
+		b[len(b)-1] ^= 0xff
+		err = i2.ReadFrom(tlv.NewReader(bytes.NewReader(b)))
+		if err != ErrParametersDigest {
+			t.Fatalf("expect %v, got %v", ErrParametersDigest, err)
+		}
+	}
+
+	// signed interest without parameters
+	i := &Interest{
+		Name: NewName("/A"),
+	}
+	err := SignInterest(ed25519Key, i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	err = i.WriteTo(tlv.NewWriter(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i2 := new(Interest)
+	err = i2.ReadFrom(tlv.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyInterest(ed25519Key, i2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i2.Name = NewName("/B").Append(ComponentTypeParametersSHA256Digest, i2.Name.Components[1])
+	err = VerifyInterest(ed25519Key, i2)
+	if err != ErrInvalidSignature {
+		t.Fatalf("expect %v, got %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestInterestVerifier(t *testing.T) {
+	v := NewInterestVerifier()
+	sign := func(seqNum uint64, at time.Time) *Interest {
+		i := &Interest{
+			Name: NewName("/A"),
+		}
+		if seqNum != 0 {
+			i.SignatureInfo = &InterestSignatureInfo{
+				SignatureSeqNum: seqNum,
+			}
+		}
+		err := SignInterest(ed25519Key, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !at.IsZero() {
+			i.SignatureInfo.SignatureTime = uint64(at.UnixNano() / 1000000)
+			i.SignatureValue, err = ed25519Key.Sign(signedInterest{i})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return i
+	}
+
+	now := time.Now()
+	i := sign(0, time.Time{})
+	for _, test := range []struct {
+		i    *Interest
+		want error
+	}{
+		{i, nil},
+		{i, ErrReplay},
+		{sign(0, time.Time{}), nil},
+		{sign(0, now.Add(-2*time.Minute)), ErrReplay},
+		{sign(0, now.Add(2*time.Minute)), ErrReplay},
+		{sign(5, time.Time{}), nil},
+		{sign(5, time.Time{}), ErrReplay},
+		{sign(6, time.Time{}), nil},
+	} {
+		err := v.Verify(ed25519Key, test.i)
+		if err != test.want {
+			t.Fatalf("Verify(%v) == %v, got %v", test.i.SignatureInfo, test.want, err)
+		}
+	}
+
+	// keys and nonces are forgotten after the grace period
+	v.mu.Lock()
+	v.expire(now.Add(v.GracePeriod + time.Second))
+	if len(v.last) != 0 || len(v.keys) != 0 || len(v.nonces) != 0 || len(v.queue) != 0 {
+		t.Fatalf("expect empty verifier, got %d keys and %d nonces", len(v.last), len(v.nonces))
+	}
+	v.mu.Unlock()
+}
@@ -0,0 +1,347 @@
+package ndn
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Errors introduced by WebSocket face.
+var (
+	ErrWebSocketHandshake = errors.New("websocket handshake failed")
+	ErrWebSocketFrame     = errors.New("invalid websocket frame")
+)
+
+// webSocketGUID is used to compute Sec-WebSocket-Accept.
+//
+// See https://tools.ietf.org/html/rfc6455#section-1.3.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// DialWebSocket creates a face to a WebSocket server at rawurl,
+// such as "ws://localhost:9696".
+//
+// Each packet is sent in one binary message.
+// See NewFace.
+func DialWebSocket(rawurl string, recv chan<- *Interest) (Face, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "ws":
+			host = net.JoinHostPort(u.Hostname(), "80")
+		case "wss":
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		conn, err = net.Dial("tcp", host)
+	case "wss":
+		conn, err = tls.Dial("tcp", host, &tls.Config{
+			ServerName: u.Hostname(),
+		})
+	default:
+		return nil, ErrNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method:     "GET",
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-Websocket-Key":     {key},
+			"Sec-Websocket-Version": {"13"},
+		},
+		Host: u.Host,
+	}
+	err = req.Write(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!headerContains(resp.Header, "Connection", "upgrade") ||
+		!headerContains(resp.Header, "Upgrade", "websocket") ||
+		resp.Header.Get("Sec-Websocket-Accept") != webSocketAccept(key) {
+		conn.Close()
+		return nil, ErrWebSocketHandshake
+	}
+	return newWebSocketFace(conn, br, true, recv), nil
+}
+
+// UpgradeWebSocket upgrades an HTTP request from a WebSocket client,
+// and creates a face on the connection.
+//
+// If the request is not a valid WebSocket handshake, an HTTP error is
+// replied, and ErrWebSocketHandshake is returned.
+// See NewFace.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request, recv chan<- *Interest) (Face, error) {
+	key := r.Header.Get("Sec-Websocket-Key")
+	if r.Method != "GET" ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-Websocket-Version") != "13" || key == "" {
+		http.Error(w, "bad websocket handshake", http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, ErrNotSupported
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", webSocketAccept(key))
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newWebSocketFace(conn, rw.Reader, false, recv), nil
+}
+
+func newWebSocketFace(conn net.Conn, br *bufio.Reader, client bool, recv chan<- *Interest) Face {
+	ws := &webSocketConn{
+		Conn:   conn,
+		br:     br,
+		client: client,
+	}
+	// every message is one packet, so there is no need to fragment
+	return NewFace(newDatagramConn(ws, nil, maxDatagramSize), recv)
+}
+
+func webSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// webSocketConn reads and writes one binary message at a time.
+//
+// A message larger than the read buffer is truncated like a datagram.
+// Text messages are dropped.
+type webSocketConn struct {
+	net.Conn
+	br     *bufio.Reader
+	client bool // client frames are masked
+
+	wm sync.Mutex
+}
+
+func (c *webSocketConn) Read(b []byte) (int, error) {
+	var (
+		msg    []byte
+		opcode byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return 0, io.EOF
+		case opPing:
+			err = c.writeFrame(opPong, payload)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		case opPong:
+			continue
+		case opContinuation:
+			if opcode == 0 {
+				return 0, ErrWebSocketFrame
+			}
+		case opText, opBinary:
+			if opcode != 0 {
+				return 0, ErrWebSocketFrame
+			}
+			opcode = op
+		default:
+			return 0, ErrWebSocketFrame
+		}
+		if len(msg)+len(payload) > maxDatagramSize {
+			return 0, ErrWebSocketFrame
+		}
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+		if opcode == opText {
+			msg, opcode = nil, 0
+			continue
+		}
+		return copy(b, msg), nil
+	}
+}
+
+// readFrame reads one frame, and unmasks its payload.
+//
+// Frames from a client must be masked, and frames from a server must not.
+func (c *webSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	_, err = io.ReadFull(c.br, hdr[:])
+	if err != nil {
+		return
+	}
+	fin = hdr[0]&0x80 != 0
+	opcode = hdr[0] & 0xf
+	masked := hdr[1]&0x80 != 0
+	if masked == c.client {
+		err = ErrWebSocketFrame
+		return
+	}
+	l := uint64(hdr[1] & 0x7f)
+	switch l {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		l = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		l = binary.BigEndian.Uint64(ext[:])
+	}
+	if err != nil {
+		return
+	}
+	if l > maxDatagramSize || opcode >= opClose && (!fin || l > 125) {
+		err = ErrWebSocketFrame
+		return
+	}
+	var mask [4]byte
+	if masked {
+		_, err = io.ReadFull(c.br, mask[:])
+		if err != nil {
+			return
+		}
+	}
+	payload = make([]byte, l)
+	_, err = io.ReadFull(c.br, payload)
+	if err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+func (c *webSocketConn) Write(b []byte) (int, error) {
+	err := c.writeFrame(opBinary, b)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// writeFrame writes one final frame, which is masked if c is a client.
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|opcode)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch l := len(payload); {
+	case l <= 125:
+		buf = append(buf, maskBit|byte(l))
+	case l <= 0xffff:
+		buf = append(buf, maskBit|126, byte(l>>8), byte(l))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = buf[:len(buf)+8]
+		binary.BigEndian.PutUint64(buf[len(buf)-8:], uint64(l))
+	}
+	if c.client {
+		var mask [4]byte
+		_, err := rand.Read(mask[:])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, mask[:]...)
+		for i, b := range payload {
+			buf = append(buf, b^mask[i%4])
+		}
+	} else {
+		buf = append(buf, payload...)
+	}
+
+	c.wm.Lock()
+	defer c.wm.Unlock()
+	_, err := c.Conn.Write(buf)
+	return err
+}
+
+// Close sends a close frame, and closes the connection.
+func (c *webSocketConn) Close() error {
+	c.writeFrame(opClose, nil)
+	return c.Conn.Close()
+}
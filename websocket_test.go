@@ -0,0 +1,106 @@
+package ndn
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebSocketFace(t *testing.T) {
+	type accepted struct {
+		Face
+		recv <-chan *Interest
+	}
+	faces := make(chan accepted, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recv := make(chan *Interest)
+		f, err := UpgradeWebSocket(w, r, recv)
+		if err != nil {
+			return
+		}
+		faces <- accepted{Face: f, recv: recv}
+	}))
+	defer srv.Close()
+
+	// plain http request is rejected
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	consumer, err := DialWebSocket("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+	producer := <-faces
+	defer producer.Close()
+	testDatagramFace(t, consumer, producer.Face, producer.recv)
+
+	// remote close
+	consumer.Close()
+	for range producer.recv {
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// no Upgrade and Connection header
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+			"Sec-WebSocket-Accept: %s\r\n\r\n", webSocketAccept(r.Header.Get("Sec-Websocket-Key")))
+		rw.Flush()
+	}))
+	defer srv.Close()
+
+	_, err := DialWebSocket("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != ErrWebSocketHandshake {
+		t.Fatalf("expect %v, got %v", ErrWebSocketHandshake, err)
+	}
+}
+
+func TestWebSocketFrame(t *testing.T) {
+	for _, client := range []bool{false, true} {
+		c1, c2 := net.Pipe()
+		ws := &webSocketConn{
+			Conn:   c2,
+			br:     bufio.NewReader(c2),
+			client: client,
+		}
+		// server frames are not masked, and client frames are
+		frame := []byte{0x82, 0x01, 0x05}
+		if !client {
+			frame = []byte{0x82, 0x81, 0, 0, 0, 0, 0x05}
+		}
+		go c1.Write(frame)
+		_, err := ws.Read(make([]byte, 16))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the other way is rejected
+		frame = []byte{0x82, 0x01, 0x05}
+		if client {
+			frame = []byte{0x82, 0x81, 0, 0, 0, 0, 0x05}
+		}
+		go c1.Write(frame)
+		_, err = ws.Read(make([]byte, 16))
+		if err != ErrWebSocketFrame {
+			t.Fatalf("expect %v, got %v", ErrWebSocketFrame, err)
+		}
+		c1.Close()
+		c2.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package ndn
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// CongestionWindow limits the number of outstanding interests.
+//
+// See https://named-data.net/publications/practical_congestion_control_scheme/.
+type CongestionWindow interface {
+	Size() int
+	// Increase is invoked when data is received.
+	Increase()
+	// Decrease is invoked when congestion is detected.
+	Decrease()
+}
+
+const (
+	initialWindow = 2.0
+	minWindow     = 2.0
+)
+
+// NewAIMD creates an additive-increase/multiplicative-decrease congestion window.
+func NewAIMD() CongestionWindow {
+	return &aimd{
+		cwnd:     initialWindow,
+		ssthresh: math.MaxFloat64,
+	}
+}
+
+type aimd struct {
+	cwnd     float64
+	ssthresh float64
+	sync.Mutex
+}
+
+func (w *aimd) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	return int(w.cwnd)
+}
+
+func (w *aimd) Increase() {
+	w.Lock()
+	defer w.Unlock()
+	if w.cwnd < w.ssthresh {
+		// slow start
+		w.cwnd++
+	} else {
+		w.cwnd += 1 / w.cwnd
+	}
+}
+
+func (w *aimd) Decrease() {
+	w.Lock()
+	defer w.Unlock()
+	w.ssthresh = math.Max(w.cwnd/2, minWindow)
+	w.cwnd = w.ssthresh
+}
+
+// CUBIC parameters from RFC 8312.
+const (
+	cubicC    = 0.4
+	cubicBeta = 0.7
+)
+
+// NewCUBIC creates a CUBIC congestion window.
+//
+// See https://tools.ietf.org/html/rfc8312.
+func NewCUBIC() CongestionWindow {
+	return &cubic{
+		cwnd:     initialWindow,
+		ssthresh: math.MaxFloat64,
+	}
+}
+
+type cubic struct {
+	cwnd     float64
+	ssthresh float64
+	wmax     float64
+	epoch    time.Time // last decrease
+	sync.Mutex
+}
+
+func (w *cubic) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	return int(w.cwnd)
+}
+
+func (w *cubic) Increase() {
+	w.Lock()
+	defer w.Unlock()
+	if w.cwnd < w.ssthresh {
+		// slow start
+		w.cwnd++
+		return
+	}
+	t := time.Since(w.epoch).Seconds()
+	k := math.Cbrt(w.wmax * (1 - cubicBeta) / cubicC)
+	target := cubicC*math.Pow(t-k, 3) + w.wmax
+	if target > w.cwnd {
+		w.cwnd += (target - w.cwnd) / w.cwnd
+	} else {
+		// plateau around wmax
+		w.cwnd += 0.01 / w.cwnd
+	}
+}
+
+func (w *cubic) Decrease() {
+	w.Lock()
+	defer w.Unlock()
+	if w.cwnd < w.wmax {
+		// fast convergence
+		w.wmax = w.cwnd * (1 + cubicBeta) / 2
+	} else {
+		w.wmax = w.cwnd
+	}
+	w.ssthresh = math.Max(w.cwnd*cubicBeta, minWindow)
+	w.cwnd = w.ssthresh
+	w.epoch = time.Now()
+}